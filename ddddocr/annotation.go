@@ -0,0 +1,586 @@
+package ddddocr
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// 标注格式导入导出（COCO / YOLO / LabelMe）
+// ============================================================================
+
+// AnnotatedImage 一张图片及其检测框
+type AnnotatedImage struct {
+	Path       string      `json:"path"` // 图片路径
+	Width      int         `json:"width"`
+	Height     int         `json:"height"`
+	Detections []Detection `json:"detections"`
+}
+
+// NewAnnotatedImage 读取图片尺寸并组装标注
+func NewAnnotatedImage(path string, detections []Detection) (AnnotatedImage, error) {
+	width, height, err := imageSize(path)
+	if err != nil {
+		return AnnotatedImage{}, err
+	}
+	return AnnotatedImage{Path: path, Width: width, Height: height, Detections: detections}, nil
+}
+
+// imageSize 只解码图片头获取尺寸
+func imageSize(path string) (int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	config, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, fmt.Errorf("读取图片尺寸失败 %s: %w", path, err)
+	}
+	return config.Width, config.Height, nil
+}
+
+// labelName 获取检测框的类别名称
+func labelName(det Detection, classNames []string) string {
+	if det.ClassName != "" {
+		return det.ClassName
+	}
+	if det.ClassID >= 0 && det.ClassID < len(classNames) {
+		return classNames[det.ClassID]
+	}
+	return strconv.Itoa(det.ClassID)
+}
+
+// classIndex 类别名到序号的映射，新类别追加到末尾
+type classIndex struct {
+	names []string
+	index map[string]int
+}
+
+func newClassIndex(classNames []string) *classIndex {
+	c := &classIndex{index: make(map[string]int)}
+	for _, name := range classNames {
+		c.id(name)
+	}
+	return c
+}
+
+func (c *classIndex) id(name string) int {
+	if id, ok := c.index[name]; ok {
+		return id
+	}
+	c.index[name] = len(c.names)
+	c.names = append(c.names, name)
+	return len(c.names) - 1
+}
+
+// exportClasses 导出时的类别序号
+//
+// 提供 classNames 时按类别名映射序号（未知类别追加到末尾）；classNames 为空时原样使用 ClassID，
+// 类别名按序号取检测框的 ClassName（缺失时为序号本身）。
+type exportClasses struct {
+	classNames []string
+	classes    *classIndex
+	idNames    []string
+}
+
+func newExportClasses(classNames []string) *exportClasses {
+	return &exportClasses{classNames: classNames, classes: newClassIndex(classNames)}
+}
+
+// id 检测框的类别序号，ClassID 为负且未提供 classNames 时返回 false
+func (e *exportClasses) id(det Detection) (int, bool) {
+	if len(e.classNames) > 0 {
+		return e.classes.id(labelName(det, e.classNames)), true
+	}
+	if det.ClassID < 0 {
+		return 0, false
+	}
+	for len(e.idNames) <= det.ClassID {
+		e.idNames = append(e.idNames, strconv.Itoa(len(e.idNames)))
+	}
+	if det.ClassName != "" {
+		e.idNames[det.ClassID] = det.ClassName
+	}
+	return det.ClassID, true
+}
+
+// names 按序号排列的类别名
+func (e *exportClasses) names() []string {
+	if len(e.classNames) > 0 {
+		return e.classes.names
+	}
+	return e.idNames
+}
+
+// ----------------------------------------------------------------------------
+// COCO
+// ----------------------------------------------------------------------------
+
+type cocoDataset struct {
+	Images      []cocoImage      `json:"images"`
+	Annotations []cocoAnnotation `json:"annotations"`
+	Categories  []cocoCategory   `json:"categories"`
+}
+
+type cocoImage struct {
+	ID       int    `json:"id"`
+	FileName string `json:"file_name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+type cocoAnnotation struct {
+	ID         int        `json:"id"`
+	ImageID    int        `json:"image_id"`
+	CategoryID int        `json:"category_id"`
+	BBox       [4]float64 `json:"bbox"` // [x, y, width, height]
+	Area       float64    `json:"area"`
+	IsCrowd    int        `json:"iscrowd"`
+	Score      *float32   `json:"score,omitempty"`
+}
+
+type cocoCategory struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// ExportCOCO 将检测结果写为 COCO JSON，图片路径相对于 JSON 所在目录
+//
+// 类别 id 为类别序号+1：提供 classNames 时按类别名映射序号（未知类别追加到末尾），
+// classNames 为空时即 ClassID+1。
+func ExportCOCO(path string, images []AnnotatedImage, classNames []string) error {
+	dataset := cocoDataset{
+		Images:      []cocoImage{},
+		Annotations: []cocoAnnotation{},
+		Categories:  []cocoCategory{},
+	}
+	classes := newExportClasses(classNames)
+	baseDir := filepath.Dir(path)
+
+	annID := 1
+	for i, img := range images {
+		dataset.Images = append(dataset.Images, cocoImage{
+			ID:       i + 1,
+			FileName: relativePath(baseDir, img.Path),
+			Width:    img.Width,
+			Height:   img.Height,
+		})
+
+		for _, det := range img.Detections {
+			classID, ok := classes.id(det)
+			if !ok {
+				return fmt.Errorf("图片 %s 中存在无效的类别 %d", img.Path, det.ClassID)
+			}
+			w := float64(det.Box.X2 - det.Box.X1)
+			h := float64(det.Box.Y2 - det.Box.Y1)
+			score := det.Score
+			dataset.Annotations = append(dataset.Annotations, cocoAnnotation{
+				ID:         annID,
+				ImageID:    i + 1,
+				CategoryID: classID + 1,
+				BBox:       [4]float64{float64(det.Box.X1), float64(det.Box.Y1), w, h},
+				Area:       w * h,
+				Score:      &score,
+			})
+			annID++
+		}
+	}
+
+	for i, name := range classes.names() {
+		dataset.Categories = append(dataset.Categories, cocoCategory{ID: i + 1, Name: name})
+	}
+
+	return writeJSON(path, dataset)
+}
+
+// ImportCOCO 读取 COCO JSON，相对图片路径基于 JSON 所在目录，ClassID 为类别按 id 排序后的序号
+func ImportCOCO(path string) ([]AnnotatedImage, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var dataset cocoDataset
+	if err := json.Unmarshal(data, &dataset); err != nil {
+		return nil, nil, fmt.Errorf("解析 COCO 文件失败: %w", err)
+	}
+
+	sort.Slice(dataset.Categories, func(i, j int) bool {
+		return dataset.Categories[i].ID < dataset.Categories[j].ID
+	})
+	classNames := make([]string, len(dataset.Categories))
+	categoryIndex := make(map[int]int, len(dataset.Categories))
+	for i, cat := range dataset.Categories {
+		classNames[i] = cat.Name
+		categoryIndex[cat.ID] = i
+	}
+
+	baseDir := filepath.Dir(path)
+	images := make([]AnnotatedImage, len(dataset.Images))
+	imageIndex := make(map[int]int, len(dataset.Images))
+	for i, img := range dataset.Images {
+		images[i] = AnnotatedImage{
+			Path:   resolvePath(baseDir, img.FileName),
+			Width:  img.Width,
+			Height: img.Height,
+		}
+		imageIndex[img.ID] = i
+	}
+
+	for _, ann := range dataset.Annotations {
+		idx, ok := imageIndex[ann.ImageID]
+		if !ok {
+			return nil, nil, fmt.Errorf("标注 %d 引用了不存在的图片 %d", ann.ID, ann.ImageID)
+		}
+		classID, ok := categoryIndex[ann.CategoryID]
+		if !ok {
+			return nil, nil, fmt.Errorf("标注 %d 引用了不存在的类别 %d", ann.ID, ann.CategoryID)
+		}
+
+		det := Detection{
+			Box: BBox{
+				X1: int(math.Round(ann.BBox[0])),
+				Y1: int(math.Round(ann.BBox[1])),
+				X2: int(math.Round(ann.BBox[0] + ann.BBox[2])),
+				Y2: int(math.Round(ann.BBox[1] + ann.BBox[3])),
+			},
+			Score:     1,
+			ClassID:   classID,
+			ClassName: classNames[classID],
+		}
+		if ann.Score != nil {
+			det.Score = *ann.Score
+		}
+		images[idx].Detections = append(images[idx].Detections, det)
+	}
+
+	return images, classNames, nil
+}
+
+// ----------------------------------------------------------------------------
+// YOLO
+// ----------------------------------------------------------------------------
+
+// ExportYOLO 为每张图片写出 <文件名>.txt（class cx cy w h，归一化坐标），并写出 classes.txt
+//
+// 提供 classNames 时按类别名映射序号（未知类别追加到末尾）；classNames 为空时原样写出 ClassID，
+// classes.txt 按序号填入检测框的 ClassName（缺失时为序号本身）。
+func ExportYOLO(dir string, images []AnnotatedImage, classNames []string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("无法创建目录: %w", err)
+	}
+	classes := newExportClasses(classNames)
+
+	for _, img := range images {
+		if img.Width <= 0 || img.Height <= 0 {
+			return fmt.Errorf("图片 %s 缺少尺寸信息", img.Path)
+		}
+
+		var sb strings.Builder
+		for _, det := range img.Detections {
+			classID, ok := classes.id(det)
+			if !ok {
+				return fmt.Errorf("图片 %s 中存在无效的类别 %d", img.Path, det.ClassID)
+			}
+			cx := float64(det.Box.X1+det.Box.X2) / 2 / float64(img.Width)
+			cy := float64(det.Box.Y1+det.Box.Y2) / 2 / float64(img.Height)
+			w := float64(det.Box.X2-det.Box.X1) / float64(img.Width)
+			h := float64(det.Box.Y2-det.Box.Y1) / float64(img.Height)
+			fmt.Fprintf(&sb, "%d %.6f %.6f %.6f %.6f\n", classID, cx, cy, w, h)
+		}
+
+		labelPath := filepath.Join(dir, fileStem(img.Path)+".txt")
+		if err := os.WriteFile(labelPath, []byte(sb.String()), 0644); err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(filepath.Join(dir, "classes.txt"), []byte(strings.Join(classes.names(), "\n")+"\n"), 0644)
+}
+
+// ImportYOLO 读取 imageDir 中每张图片在 labelDir 下对应的 YOLO 标注
+//
+// 类别名取自 labelDir/classes.txt，不存在时以类别序号作为名称。没有标注文件的图片视为无目标。
+func ImportYOLO(imageDir, labelDir string) ([]AnnotatedImage, []string, error) {
+	var classNames []string
+	if data, err := os.ReadFile(filepath.Join(labelDir, "classes.txt")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				classNames = append(classNames, line)
+			}
+		}
+	}
+
+	imagePaths, err := listImages(imageDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	images := make([]AnnotatedImage, 0, len(imagePaths))
+	for _, imagePath := range imagePaths {
+		img, err := NewAnnotatedImage(imagePath, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		dets, err := readYOLOLabel(filepath.Join(labelDir, fileStem(imagePath)+".txt"), img.Width, img.Height, classNames)
+		if err != nil {
+			return nil, nil, err
+		}
+		img.Detections = dets
+		images = append(images, img)
+	}
+
+	return images, classNames, nil
+}
+
+// readYOLOLabel 读取单个 YOLO 标注文件
+func readYOLOLabel(path string, width, height int, classNames []string) ([]Detection, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var dets []Detection
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("%s:%d: 字段不足", path, lineNo)
+		}
+
+		classID, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: 无效的类别 %q", path, lineNo, fields[0])
+		}
+		var v [4]float64
+		for i := range v {
+			if v[i], err = strconv.ParseFloat(fields[i+1], 64); err != nil {
+				return nil, fmt.Errorf("%s:%d: 无效的坐标 %q", path, lineNo, fields[i+1])
+			}
+		}
+
+		cx, cy := v[0]*float64(width), v[1]*float64(height)
+		w, h := v[2]*float64(width), v[3]*float64(height)
+		det := Detection{
+			Box: BBox{
+				X1: int(math.Round(cx - w/2)),
+				Y1: int(math.Round(cy - h/2)),
+				X2: int(math.Round(cx + w/2)),
+				Y2: int(math.Round(cy + h/2)),
+			},
+			Score:   1,
+			ClassID: classID,
+		}
+		// 第 6 列为可选的置信度
+		if len(fields) >= 6 {
+			if score, err := strconv.ParseFloat(fields[5], 32); err == nil {
+				det.Score = float32(score)
+			}
+		}
+		if classID >= 0 && classID < len(classNames) {
+			det.ClassName = classNames[classID]
+		}
+		dets = append(dets, det)
+	}
+	return dets, scanner.Err()
+}
+
+// ----------------------------------------------------------------------------
+// LabelMe
+// ----------------------------------------------------------------------------
+
+type labelMeFile struct {
+	Version     string          `json:"version"`
+	Flags       map[string]bool `json:"flags"`
+	Shapes      []labelMeShape  `json:"shapes"`
+	ImagePath   string          `json:"imagePath"`
+	ImageData   *string         `json:"imageData"`
+	ImageHeight int             `json:"imageHeight"`
+	ImageWidth  int             `json:"imageWidth"`
+}
+
+type labelMeShape struct {
+	Label     string          `json:"label"`
+	Points    [][2]float64    `json:"points"`
+	GroupID   *int            `json:"group_id"`
+	ShapeType string          `json:"shape_type"`
+	Flags     map[string]bool `json:"flags"`
+}
+
+// ExportLabelMe 为每张图片写出 <文件名>.json（矩形标注），imagePath 相对于输出目录
+func ExportLabelMe(dir string, images []AnnotatedImage, classNames []string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("无法创建目录: %w", err)
+	}
+
+	for _, img := range images {
+		file := labelMeFile{
+			Version:     "5.0.1",
+			Flags:       map[string]bool{},
+			Shapes:      []labelMeShape{},
+			ImagePath:   relativePath(dir, img.Path),
+			ImageHeight: img.Height,
+			ImageWidth:  img.Width,
+		}
+		for _, det := range img.Detections {
+			file.Shapes = append(file.Shapes, labelMeShape{
+				Label: labelName(det, classNames),
+				Points: [][2]float64{
+					{float64(det.Box.X1), float64(det.Box.Y1)},
+					{float64(det.Box.X2), float64(det.Box.Y2)},
+				},
+				ShapeType: "rectangle",
+				Flags:     map[string]bool{},
+			})
+		}
+
+		if err := writeJSON(filepath.Join(dir, fileStem(img.Path)+".json"), file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportLabelMe 读取目录中的 LabelMe JSON，矩形与多边形均转为外接框
+//
+// classNames 用于固定类别序号，未列出的类别按出现顺序追加。
+func ImportLabelMe(dir string, classNames []string) ([]AnnotatedImage, []string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(paths)
+	classes := newClassIndex(classNames)
+
+	var images []AnnotatedImage
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		var file labelMeFile
+		if err := json.Unmarshal(data, &file); err != nil || file.ImagePath == "" {
+			// 跳过非 LabelMe 的 JSON 文件
+			continue
+		}
+
+		img := AnnotatedImage{
+			Path:   resolvePath(dir, file.ImagePath),
+			Width:  file.ImageWidth,
+			Height: file.ImageHeight,
+		}
+		for _, shape := range file.Shapes {
+			if len(shape.Points) < 2 {
+				continue
+			}
+			x1, y1 := math.Inf(1), math.Inf(1)
+			x2, y2 := math.Inf(-1), math.Inf(-1)
+			for _, p := range shape.Points {
+				x1, y1 = math.Min(x1, p[0]), math.Min(y1, p[1])
+				x2, y2 = math.Max(x2, p[0]), math.Max(y2, p[1])
+			}
+			img.Detections = append(img.Detections, Detection{
+				Box: BBox{
+					X1: int(math.Round(x1)),
+					Y1: int(math.Round(y1)),
+					X2: int(math.Round(x2)),
+					Y2: int(math.Round(y2)),
+				},
+				Score:     1,
+				ClassID:   classes.id(shape.Label),
+				ClassName: shape.Label,
+			})
+		}
+		images = append(images, img)
+	}
+
+	return images, classes.names, nil
+}
+
+// ----------------------------------------------------------------------------
+// 辅助函数
+// ----------------------------------------------------------------------------
+
+// writeJSON 以缩进格式写出 JSON 文件
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// relativePath 图片相对于标注文件目录的路径（斜杠分隔），无法计算相对路径时返回绝对路径
+//
+// 两者先转为绝对路径，避免相对于工作目录的图片路径被当作相对于标注目录。
+func relativePath(baseDir, path string) string {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	absBase, err := filepath.Abs(baseDir)
+	if err != nil {
+		return filepath.ToSlash(absPath)
+	}
+	rel, err := filepath.Rel(absBase, absPath)
+	if err != nil {
+		return filepath.ToSlash(absPath)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// resolvePath 将标注文件中的图片路径解析为可直接打开的路径，相对路径基于标注文件目录
+func resolvePath(baseDir, path string) string {
+	path = filepath.FromSlash(path)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// fileStem 去除目录和扩展名的文件名
+func fileStem(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// listImages 列出目录中的图片文件（按文件名排序）
+func listImages(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".jpg", ".jpeg", ".png", ".gif":
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
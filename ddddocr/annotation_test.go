@@ -0,0 +1,147 @@
+package ddddocr
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeTestImages 在 dir 下写出两张空白 PNG，返回相对于工作目录的路径（模拟调用方传入相对路径）
+func writeTestImages(t *testing.T, dir string) []AnnotatedImage {
+	t.Helper()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	images := []AnnotatedImage{
+		{Detections: []Detection{
+			{Box: BBox{X1: 10, Y1: 20, X2: 40, Y2: 60}, Score: 0.9, ClassID: 3, ClassName: "d"},
+			{Box: BBox{X1: 50, Y1: 5, X2: 70, Y2: 25}, Score: 0.8, ClassID: 0, ClassName: "a"},
+		}},
+		{Detections: []Detection{
+			{Box: BBox{X1: 0, Y1: 0, X2: 80, Y2: 40}, Score: 0.7, ClassID: 1, ClassName: "b"},
+		}},
+	}
+	for i := range images {
+		path := filepath.Join(dir, []string{"first.png", "second.png"}[i])
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = png.Encode(f, image.NewGray(image.Rect(0, 0, 100, 80)))
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rel, err := filepath.Rel(cwd, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		images[i].Path, images[i].Width, images[i].Height = rel, 100, 80
+	}
+	return images
+}
+
+// checkRoundTrip 比较导入结果与导出前的标注；wantScore 为 false 时不比较分数（格式不保存分数）
+func checkRoundTrip(t *testing.T, want, got []AnnotatedImage, wantScore bool) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("导入 %d 张图片, want %d", len(got), len(want))
+	}
+	for i := range want {
+		wantPath, _ := filepath.Abs(want[i].Path)
+		gotPath, _ := filepath.Abs(got[i].Path)
+		if gotPath != wantPath {
+			t.Fatalf("图片 %d 路径 %s, want %s", i, gotPath, wantPath)
+		}
+		if got[i].Width != want[i].Width || got[i].Height != want[i].Height {
+			t.Fatalf("图片 %d 尺寸 %dx%d, want %dx%d", i, got[i].Width, got[i].Height, want[i].Width, want[i].Height)
+		}
+		if len(got[i].Detections) != len(want[i].Detections) {
+			t.Fatalf("图片 %d 检测框 %+v, want %+v", i, got[i].Detections, want[i].Detections)
+		}
+		for j, w := range want[i].Detections {
+			g := got[i].Detections[j]
+			if !wantScore {
+				g.Score = w.Score
+			}
+			if g != w {
+				t.Fatalf("图片 %d 检测框 %d = %+v, want %+v", i, j, g, w)
+			}
+		}
+	}
+}
+
+func TestCOCORoundTrip(t *testing.T) {
+	root := t.TempDir()
+	imageDir := filepath.Join(root, "images")
+	if err := os.Mkdir(imageDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	images := writeTestImages(t, imageDir)
+
+	// 标注文件与图片不在同一目录
+	path := filepath.Join(root, "annotations", "coco.json")
+	if err := os.Mkdir(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExportCOCO(path, images, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got, classNames, err := ImportCOCO(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 类别 id 为 ClassID+1，中间缺失的序号以序号本身命名
+	if want := []string{"a", "b", "2", "d"}; !slices.Equal(classNames, want) {
+		t.Fatalf("classNames = %v, want %v", classNames, want)
+	}
+	checkRoundTrip(t, images, got, true)
+}
+
+func TestYOLORoundTrip(t *testing.T) {
+	root := t.TempDir()
+	images := writeTestImages(t, root)
+	labelDir := filepath.Join(root, "labels")
+
+	if err := ExportYOLO(labelDir, images, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, classNames, err := ImportYOLO(root, labelDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "2", "d"}; !slices.Equal(classNames, want) {
+		t.Fatalf("classNames = %v, want %v", classNames, want)
+	}
+	checkRoundTrip(t, images, got, false)
+}
+
+func TestLabelMeRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	imageDir := filepath.Join(root, "images")
+	if err := os.Mkdir(imageDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	images := writeTestImages(t, imageDir)
+
+	outDir := filepath.Join(root, "labelme")
+	classNames := []string{"a", "b", "c", "d"}
+	if err := ExportLabelMe(outDir, images, classNames); err != nil {
+		t.Fatal(err)
+	}
+	got, names, err := ImportLabelMe(outDir, classNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(names, classNames) {
+		t.Fatalf("classNames = %v, want %v", names, classNames)
+	}
+	checkRoundTrip(t, images, got, false)
+}
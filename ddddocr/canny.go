@@ -0,0 +1,450 @@
+package ddddocr
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+)
+
+// ============================================================================
+// Canny 边缘检测
+// ============================================================================
+
+// CannyOptions Canny 边缘检测选项
+type CannyOptions struct {
+	LowThreshold  float64 // 低阈值，大于高阈值时两者互换
+	HighThreshold float64 // 高阈值
+	ApertureSize  int     // Sobel 孔径: 3、5、7，0 表示 3
+	L2Gradient    bool    // 梯度幅值使用 sqrt(dx²+dy²)，默认与 OpenCV 一样使用 |dx|+|dy|
+
+	BlurSize  int     // 高斯预模糊核尺寸（奇数），0 表示不模糊
+	BlurSigma float64 // 高斯标准差，0 表示按 OpenCV 公式由核尺寸推算
+
+	AutoThreshold bool    // 按灰度中位数自动计算阈值，忽略 LowThreshold / HighThreshold
+	AutoSigma     float64 // 自动阈值的浮动比例，默认 0.33
+
+	Color bool // 与 OpenCV 处理彩色图一致：逐像素取梯度幅值最大的通道
+}
+
+// DefaultCannyOptions 默认 Canny 选项，对应 cv2.Canny(img, 100, 200) 的参数
+func DefaultCannyOptions() CannyOptions {
+	return CannyOptions{
+		LowThreshold:  100,
+		HighThreshold: 200,
+		ApertureSize:  3,
+	}
+}
+
+// Canny 边缘检测，返回边缘像素为 255 的二值图
+//
+// 按 cv2.Canny 的算法实现：Sobel 梯度（复制边界）、按 22.5° 分区的非极大值抑制、
+// 基于栈的滞后阈值连接（8 邻域）。与 cv2.Canny 的逐像素比较见 TestCannyMatchesOpenCV，
+// 参考边缘图由 scripts/canny_golden.py 生成。
+func Canny(img image.Image, opts CannyOptions) (*image.Gray, error) {
+	if err := validateCannyOptions(opts); err != nil {
+		return nil, err
+	}
+	return canny(img, opts), nil
+}
+
+// validateCannyOptions 校验孔径与高斯核尺寸
+func validateCannyOptions(opts CannyOptions) error {
+	switch opts.ApertureSize {
+	case 0, 3, 5, 7:
+	default:
+		return fmt.Errorf("Sobel 孔径必须为 3、5 或 7: %d", opts.ApertureSize)
+	}
+	if opts.BlurSize < 0 || (opts.BlurSize > 0 && opts.BlurSize%2 == 0) {
+		return fmt.Errorf("高斯核尺寸必须为正奇数: %d", opts.BlurSize)
+	}
+	return nil
+}
+
+// canny Canny 边缘检测（选项已校验）
+func canny(img image.Image, opts CannyOptions) *image.Gray {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	result := image.NewGray(image.Rect(0, 0, w, h))
+	if w == 0 || h == 0 {
+		return result
+	}
+
+	aperture := opts.ApertureSize
+	if aperture == 0 {
+		aperture = 3
+	}
+
+	// 1. 取通道并预模糊
+	var channels [][]int32
+	if opts.Color {
+		r, g, b := rgbPlanes(img)
+		channels = [][]int32{r, g, b}
+	} else {
+		channels = [][]int32{cvGrayPlane(img)}
+	}
+	if opts.BlurSize > 1 {
+		for i := range channels {
+			channels[i] = gaussianBlurPlane(channels[i], w, h, opts.BlurSize, opts.BlurSigma)
+		}
+	}
+
+	// 2. 阈值
+	low, high := opts.LowThreshold, opts.HighThreshold
+	if opts.AutoThreshold {
+		gray := channels[0]
+		if opts.Color {
+			gray = cvGrayPlane(img)
+			if opts.BlurSize > 1 {
+				gray = gaussianBlurPlane(gray, w, h, opts.BlurSize, opts.BlurSigma)
+			}
+		}
+		low, high = medianThresholds(gray, opts.AutoSigma)
+	}
+	if low > high {
+		low, high = high, low
+	}
+	if opts.L2Gradient {
+		low, high = math.Min(low, 32767), math.Min(high, 32767)
+		if low > 0 {
+			low *= low
+		}
+		if high > 0 {
+			high *= high
+		}
+	}
+	lowThr, highThr := int64(math.Floor(low)), int64(math.Floor(high))
+
+	// 3. 梯度：多通道时取幅值最大的通道
+	dx := make([]int32, w*h)
+	dy := make([]int32, w*h)
+	mag := make([]int64, w*h)
+	for c, ch := range channels {
+		cdx, cdy := sobelPlanes(ch, w, h, aperture)
+		for i := range cdx {
+			m := gradientMagnitude(cdx[i], cdy[i], opts.L2Gradient)
+			if c == 0 || m > mag[i] {
+				dx[i], dy[i], mag[i] = cdx[i], cdy[i], m
+			}
+		}
+	}
+
+	// 4. 非极大值抑制，图像外的幅值视为 0
+	const (
+		cannyShift = 15
+		tg22       = 13573 // tan(22.5°)·2^15，四舍五入
+	)
+	magAt := func(x, y int) int64 {
+		if x < 0 || y < 0 || x >= w || y >= h {
+			return 0
+		}
+		return mag[y*w+x]
+	}
+
+	const (
+		edgeNone   = 0
+		edgeWeak   = 1
+		edgeStrong = 2
+	)
+	edgeMap := make([]uint8, w*h)
+	var stack []int
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			m := mag[i]
+			if m <= lowThr {
+				continue
+			}
+
+			xs := int64(dx[i])
+			ys := int64(dy[i])
+			if xs < 0 {
+				xs = -xs
+			}
+			if ys < 0 {
+				ys = -ys
+			}
+			ys <<= cannyShift
+			tg22x := xs * tg22
+
+			isMax := false
+			if ys < tg22x {
+				isMax = m > magAt(x-1, y) && m >= magAt(x+1, y)
+			} else {
+				tg67x := tg22x + (xs << (cannyShift + 1))
+				if ys > tg67x {
+					isMax = m > magAt(x, y-1) && m >= magAt(x, y+1)
+				} else {
+					s := 1
+					if (dx[i] ^ dy[i]) < 0 {
+						s = -1
+					}
+					isMax = m > magAt(x-s, y-1) && m > magAt(x+s, y+1)
+				}
+			}
+			if !isMax {
+				continue
+			}
+
+			if m > highThr {
+				edgeMap[i] = edgeStrong
+				stack = append(stack, i)
+			} else {
+				edgeMap[i] = edgeWeak
+			}
+		}
+	}
+
+	// 5. 滞后阈值：从强边缘出发，沿 8 邻域把相连的弱边缘提升为强边缘
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		x, y := i%w, i/w
+
+		for ny := y - 1; ny <= y+1; ny++ {
+			for nx := x - 1; nx <= x+1; nx++ {
+				if nx < 0 || ny < 0 || nx >= w || ny >= h {
+					continue
+				}
+				j := ny*w + nx
+				if edgeMap[j] == edgeWeak {
+					edgeMap[j] = edgeStrong
+					stack = append(stack, j)
+				}
+			}
+		}
+	}
+
+	for i, e := range edgeMap {
+		if e == edgeStrong {
+			result.Pix[(i/w)*result.Stride+i%w] = 255
+		}
+	}
+	return result
+}
+
+// gradientMagnitude L1 或 L2（平方）梯度幅值
+func gradientMagnitude(dx, dy int32, l2 bool) int64 {
+	x, y := int64(dx), int64(dy)
+	if l2 {
+		return x*x + y*y
+	}
+	if x < 0 {
+		x = -x
+	}
+	if y < 0 {
+		y = -y
+	}
+	return x + y
+}
+
+// cvGrayPlane 按 OpenCV 定点公式转灰度: (R·4899 + G·9617 + B·1868 + 8192) >> 14
+func cvGrayPlane(img image.Image) []int32 {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	gray := make([]int32, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, b, _ := img.At(x+bounds.Min.X, y+bounds.Min.Y).RGBA()
+			gray[y*w+x] = int32((int(r>>8)*4899 + int(g>>8)*9617 + int(b>>8)*1868 + 8192) >> 14)
+		}
+	}
+	return gray
+}
+
+// rgbPlanes 拆分 R、G、B 通道
+func rgbPlanes(img image.Image) ([]int32, []int32, []int32) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	r := make([]int32, w*h)
+	g := make([]int32, w*h)
+	b := make([]int32, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			cr, cg, cb, _ := img.At(x+bounds.Min.X, y+bounds.Min.Y).RGBA()
+			i := y*w + x
+			r[i], g[i], b[i] = int32(cr>>8), int32(cg>>8), int32(cb>>8)
+		}
+	}
+	return r, g, b
+}
+
+// medianThresholds 按中位数计算自动阈值: [(1-σ)·median, (1+σ)·median]
+func medianThresholds(gray []int32, sigma float64) (float64, float64) {
+	if sigma <= 0 {
+		sigma = 0.33
+	}
+	if len(gray) == 0 {
+		return 0, 0
+	}
+
+	sorted := make([]int32, len(gray))
+	copy(sorted, gray)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := float64(sorted[len(sorted)/2])
+
+	low := math.Max(0, (1-sigma)*median)
+	high := math.Min(255, (1+sigma)*median)
+	return low, high
+}
+
+// sobelKernels 一阶 Sobel 的平滑核与差分核（与 cv2.getDerivKernels 一致）
+func sobelKernels(aperture int) ([]int32, []int32) {
+	smooth := binomialKernel(aperture - 1)
+	base := binomialKernel(aperture - 3)
+
+	// 差分核 = 二项式核 ⊗ [-1, 0, 1]
+	deriv := make([]int32, aperture)
+	for i, v := range base {
+		deriv[i] -= v
+		deriv[i+2] += v
+	}
+	return smooth, deriv
+}
+
+// binomialKernel n 阶二项式系数，长度 n+1
+func binomialKernel(n int) []int32 {
+	k := []int32{1}
+	for i := 0; i < n; i++ {
+		next := make([]int32, len(k)+1)
+		for j, v := range k {
+			next[j] += v
+			next[j+1] += v
+		}
+		k = next
+	}
+	return k
+}
+
+// sobelPlanes 计算 x、y 方向 Sobel 梯度（复制边界，结果饱和到 int16）
+func sobelPlanes(src []int32, w, h, aperture int) ([]int32, []int32) {
+	smooth, deriv := sobelKernels(aperture)
+	dx := separableFilter(src, w, h, deriv, smooth, clampIndex)
+	dy := separableFilter(src, w, h, smooth, deriv, clampIndex)
+	for i := range dx {
+		dx[i] = saturateInt16(dx[i])
+		dy[i] = saturateInt16(dy[i])
+	}
+	return dx, dy
+}
+
+// separableFilter 可分离卷积：先按行应用 kx，再按列应用 ky
+func separableFilter(src []int32, w, h int, kx, ky []int32, border func(i, n int) int) []int32 {
+	rx, ry := len(kx)/2, len(ky)/2
+
+	tmp := make([]int32, w*h)
+	for y := 0; y < h; y++ {
+		row := src[y*w : (y+1)*w]
+		for x := 0; x < w; x++ {
+			var sum int32
+			for k, c := range kx {
+				sum += c * row[border(x+k-rx, w)]
+			}
+			tmp[y*w+x] = sum
+		}
+	}
+
+	dst := make([]int32, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sum int32
+			for k, c := range ky {
+				sum += c * tmp[border(y+k-ry, h)*w+x]
+			}
+			dst[y*w+x] = sum
+		}
+	}
+	return dst
+}
+
+// gaussianBlurPlane 高斯模糊（BORDER_REFLECT_101，结果四舍五入到 0-255）
+//
+// 使用与 OpenCV 8 位图像相同的定点核（8 位小数），逐行、逐列累加后舍入。
+func gaussianBlurPlane(src []int32, w, h, size int, sigma float64) []int32 {
+	const shift = 8
+	kernel := gaussianKernelFixed(size, sigma, shift)
+
+	out := separableFilter(src, w, h, kernel, kernel, reflectIndex)
+	for i, v := range out {
+		v = (v + 1<<(2*shift-1)) >> (2 * shift)
+		out[i] = max(0, min(255, v))
+	}
+	return out
+}
+
+// smallGaussianKernels OpenCV 在 sigma<=0 且核尺寸不超过 7 时使用的固定高斯核
+var smallGaussianKernels = map[int][]float64{
+	1: {1},
+	3: {0.25, 0.5, 0.25},
+	5: {0.0625, 0.25, 0.375, 0.25, 0.0625},
+	7: {0.03125, 0.109375, 0.21875, 0.28125, 0.21875, 0.109375, 0.03125},
+}
+
+// gaussianKernelFixed 生成系数和恰为 1<<shift 的定点高斯核（size 为奇数）
+//
+// 与 cv::getGaussianKernel 相同：sigma<=0 时小核取固定表，否则按 0.3·((size-1)·0.5-1)+0.8 推算 sigma；
+// 定点化与 OpenCV 一样从两端向中心做误差扩散舍入，中心系数取剩余值。
+func gaussianKernelFixed(size int, sigma float64, shift int) []int32 {
+	weights, ok := smallGaussianKernels[size]
+	if !ok || sigma > 0 {
+		if sigma <= 0 {
+			sigma = 0.3*(float64(size-1)*0.5-1) + 0.8
+		}
+		weights = make([]float64, size)
+		var total float64
+		for i := range weights {
+			d := float64(i - size/2)
+			weights[i] = math.Exp(-d * d / (2 * sigma * sigma))
+			total += weights[i]
+		}
+		for i := range weights {
+			weights[i] /= total
+		}
+	}
+
+	one := int32(1) << shift
+	kernel := make([]int32, size)
+	var err float64
+	var sum int32
+	for i := 0; i < size/2; i++ {
+		v := weights[i]*float64(one) + err
+		kernel[i] = int32(math.RoundToEven(v))
+		err = v - float64(kernel[i])
+		kernel[size-1-i] = kernel[i]
+		sum += 2 * kernel[i]
+	}
+	kernel[size/2] = one - sum
+	return kernel
+}
+
+// clampIndex 复制边界 (BORDER_REPLICATE)
+func clampIndex(i, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
+
+// reflectIndex 镜像边界，不重复边缘像素 (BORDER_REFLECT_101)
+func reflectIndex(i, n int) int {
+	if n == 1 {
+		return 0
+	}
+	for i < 0 || i >= n {
+		if i < 0 {
+			i = -i
+		}
+		if i >= n {
+			i = 2*(n-1) - i
+		}
+	}
+	return i
+}
+
+func saturateInt16(v int32) int32 {
+	return max(math.MinInt16, min(math.MaxInt16, v))
+}
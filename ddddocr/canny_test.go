@@ -0,0 +1,133 @@
+package ddddocr
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// cannyGoldenCases 与 scripts/canny_golden.py 中的用例一一对应
+var cannyGoldenCases = map[string]CannyOptions{
+	"gray":   DefaultCannyOptions(),
+	"color":  {LowThreshold: 100, HighThreshold: 200, Color: true},
+	"blur5":  {LowThreshold: 100, HighThreshold: 200, BlurSize: 5},
+	"blur9":  {LowThreshold: 50, HighThreshold: 150, BlurSize: 9},
+	"l2_ap5": {LowThreshold: 400, HighThreshold: 1000, ApertureSize: 5, L2Gradient: true},
+}
+
+func readTestPNG(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// TestCannyMatchesOpenCV 与 cv2.Canny 生成的参考边缘图逐像素比较，参考图缺失时失败
+func TestCannyMatchesOpenCV(t *testing.T) {
+	inputs, _ := filepath.Glob(filepath.Join("testdata", "canny", "*_input.png"))
+	if len(inputs) == 0 {
+		t.Fatal("缺少参考边缘图，需安装 OpenCV 后运行 python3 scripts/canny_golden.py 生成并提交 ddddocr/testdata/canny")
+	}
+
+	for _, input := range inputs {
+		src := readTestPNG(t, input)
+		prefix := input[:len(input)-len("input.png")]
+		for name, opts := range cannyGoldenCases {
+			t.Run(filepath.Base(prefix)+name, func(t *testing.T) {
+				want := readTestPNG(t, prefix+name+".png")
+				got, err := Canny(src, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				b := want.Bounds()
+				if b.Dx() != got.Rect.Dx() || b.Dy() != got.Rect.Dy() {
+					t.Fatalf("尺寸 %v, 参考 %v", got.Rect.Size(), b.Size())
+				}
+				diff := 0
+				var first image.Point
+				for y := 0; y < b.Dy(); y++ {
+					for x := 0; x < b.Dx(); x++ {
+						r, _, _, _ := want.At(b.Min.X+x, b.Min.Y+y).RGBA()
+						if (r > 0x7fff) != (got.GrayAt(x, y).Y > 0) {
+							if diff == 0 {
+								first = image.Point{X: x, Y: y}
+							}
+							diff++
+						}
+					}
+				}
+				if diff > 0 {
+					t.Fatalf("%d 个像素与 OpenCV 不一致，首个位于 %v", diff, first)
+				}
+			})
+		}
+	}
+}
+
+// 竖直阶跃边缘两侧梯度相等，OpenCV 的非极大值抑制只保留左侧一列
+func TestCannyVerticalStep(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 20, 12))
+	for y := 0; y < 12; y++ {
+		for x := 10; x < 20; x++ {
+			img.Pix[y*img.Stride+x] = 255
+		}
+	}
+
+	for _, opts := range []CannyOptions{DefaultCannyOptions(), {LowThreshold: 100, HighThreshold: 200, L2Gradient: true}} {
+		edges, err := Canny(img, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for y := 0; y < 12; y++ {
+			for x := 0; x < 20; x++ {
+				if want := x == 9; (edges.GrayAt(x, y).Y > 0) != want {
+					t.Fatalf("L2=%v (%d,%d) 边缘 = %v, want %v", opts.L2Gradient, x, y, !want, want)
+				}
+			}
+		}
+	}
+}
+
+func TestGaussianKernelFixed(t *testing.T) {
+	// OpenCV 8 位图像的固定小核
+	for size, want := range map[int][]int32{
+		3: {64, 128, 64},
+		5: {16, 64, 96, 64, 16},
+		7: {8, 28, 56, 72, 56, 28, 8},
+	} {
+		if got := gaussianKernelFixed(size, 0, 8); !slices.Equal(got, want) {
+			t.Fatalf("size %d: %v, want %v", size, got, want)
+		}
+	}
+
+	for _, tc := range []struct {
+		size  int
+		sigma float64
+	}{{9, 0}, {11, 0}, {5, 1.5}, {7, 3}} {
+		kernel := gaussianKernelFixed(tc.size, tc.sigma, 8)
+		var sum int32
+		for i, v := range kernel {
+			sum += v
+			if v != kernel[tc.size-1-i] {
+				t.Fatalf("size %d sigma %v 不对称: %v", tc.size, tc.sigma, kernel)
+			}
+			if i > 0 && i <= tc.size/2 && v < kernel[i-1] {
+				t.Fatalf("size %d sigma %v 不是单峰: %v", tc.size, tc.sigma, kernel)
+			}
+		}
+		if sum != 256 {
+			t.Fatalf("size %d sigma %v 系数和 %d: %v", tc.size, tc.sigma, sum, kernel)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package ddddocr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+	"sort"
+
+	"github.com/nfnt/resize"
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// ============================================================================
+// 图像分类功能
+// ============================================================================
+
+// LabelScore 分类结果
+type LabelScore struct {
+	Label string  `json:"label"`
+	Index int     `json:"index"`
+	Score float32 `json:"score"`
+}
+
+// classifierConfig 图像分类模型配置
+//
+//	{
+//	  "labels": ["bus", "car", "bicycle"],
+//	  "image": [224, 224],
+//	  "channel": 3,
+//	  "mean": [0.485, 0.456, 0.406],
+//	  "std": [0.229, 0.224, 0.225],
+//	  "top_k": 3,
+//	  "output_activation": "logits"
+//	}
+type classifierConfig struct {
+	Labels           []string  `json:"labels"`
+	Image            []int     `json:"image"` // [width, height]
+	Channel          int       `json:"channel"`
+	Mean             []float32 `json:"mean"`
+	Std              []float32 `json:"std"`
+	TopK             int       `json:"top_k"`
+	OutputActivation string    `json:"output_activation"`
+}
+
+// initClassifierMode 初始化图像分类模式
+func (d *DdddOcr) initClassifierMode(opts Options) (*DdddOcr, error) {
+	if opts.ClassifierConfigPath == "" {
+		return nil, fmt.Errorf("图像分类模型需要提供配置路径")
+	}
+
+	data, err := os.ReadFile(opts.ClassifierConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取分类配置失败: %w", err)
+	}
+
+	config := classifierConfig{
+		Image:   []int{224, 224},
+		Channel: 3,
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("解析分类配置失败: %w", err)
+	}
+	if len(config.Labels) == 0 {
+		return nil, fmt.Errorf("分类配置缺少 labels")
+	}
+	if len(config.Image) < 2 || config.Image[0] <= 0 || config.Image[1] <= 0 {
+		return nil, fmt.Errorf("分类配置 image 必须为 [width, height]")
+	}
+	if config.Channel != 1 && config.Channel != 3 {
+		return nil, fmt.Errorf("不支持的通道数: %d", config.Channel)
+	}
+	if len(config.Mean) == 0 {
+		config.Mean = []float32{0.5}
+	}
+	if len(config.Std) == 0 {
+		config.Std = []float32{0.5}
+	}
+	switch config.OutputActivation {
+	case OutputActivationAuto, OutputActivationLogits, OutputActivationSoftmax, OutputActivationLogSoftmax:
+	default:
+		return nil, fmt.Errorf("不支持的输出激活类型: %s", config.OutputActivation)
+	}
+	d.clsConfig = config
+
+	d.clsSession, d.clsOptions, err = newModelSession(opts.ClassifierPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载分类模型失败: %w", err)
+	}
+
+	d.isClsMode = true
+	return d, nil
+}
+
+// Classify 图像分类，按分数降序返回前 top_k 个标签
+func (d *DdddOcr) Classify(imageData []byte) ([]LabelScore, error) {
+	img, _, err := image.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("解码失败: %w", err)
+	}
+	return d.classifyImage(img)
+}
+
+// ClassifyGrid 将宫格图切分为 rows×cols 块并逐块分类，结果按行优先顺序排列
+func (d *DdddOcr) ClassifyGrid(imageData []byte, rows, cols int) ([][]LabelScore, error) {
+	img, _, err := image.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("解码失败: %w", err)
+	}
+
+	tiles, err := SplitGrid(img, rows, cols)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]LabelScore, len(tiles))
+	for i, tile := range tiles {
+		results[i], err = d.classifyImage(tile)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 块分类失败: %w", i, err)
+		}
+	}
+	return results, nil
+}
+
+// SplitGrid 将图片均分为 rows×cols 块，按行优先顺序返回
+func SplitGrid(img image.Image, rows, cols int) ([]image.Image, error) {
+	bounds := img.Bounds()
+	if rows <= 0 || cols <= 0 || rows > bounds.Dy() || cols > bounds.Dx() {
+		return nil, fmt.Errorf("无效的宫格尺寸: %dx%d", rows, cols)
+	}
+
+	tiles := make([]image.Image, 0, rows*cols)
+	for r := 0; r < rows; r++ {
+		y1 := bounds.Min.Y + r*bounds.Dy()/rows
+		y2 := bounds.Min.Y + (r+1)*bounds.Dy()/rows
+		for c := 0; c < cols; c++ {
+			x1 := bounds.Min.X + c*bounds.Dx()/cols
+			x2 := bounds.Min.X + (c+1)*bounds.Dx()/cols
+
+			tile := image.NewRGBA(image.Rect(0, 0, x2-x1, y2-y1))
+			draw.Draw(tile, tile.Bounds(), img, image.Point{X: x1, Y: y1}, draw.Src)
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles, nil
+}
+
+// classifyImage 对已解码的图片执行分类
+func (d *DdddOcr) classifyImage(img image.Image) ([]LabelScore, error) {
+	if err := d.ensureCls(); err != nil {
+		return nil, err
+	}
+
+	config := d.clsConfig
+	width, height := config.Image[0], config.Image[1]
+
+	resized := resize.Resize(uint(width), uint(height), img, resize.Bilinear)
+	inputData := imageToNormalizedFloat32(resized, width, height, config.Channel, config.Mean, config.Std)
+
+	inputShape := ort.NewShape(1, int64(config.Channel), int64(height), int64(width))
+	inputTensor, err := ort.NewTensor(inputShape, inputData)
+	if err != nil {
+		return nil, err
+	}
+	defer inputTensor.Destroy()
+
+	outputs := []ort.Value{nil}
+	err = d.clsSession.Run([]ort.Value{inputTensor}, outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	if outputs[0] == nil {
+		return nil, fmt.Errorf("无输出")
+	}
+	defer outputs[0].Destroy()
+
+	outputTensor, ok := outputs[0].(*ort.Tensor[float32])
+	if !ok {
+		return nil, fmt.Errorf("不支持的输出类型")
+	}
+
+	data := outputTensor.GetData()
+	numClasses := len(config.Labels)
+	if len(data) != numClasses {
+		return nil, fmt.Errorf("输出类别数 %d 与标签数 %d 不一致", len(data), numClasses)
+	}
+
+	probability := ctcProbability(data, ort.NewShape(1, int64(numClasses)), OutputLayoutTC, config.OutputActivation)
+	return topKLabels(probability[0], config.Labels, config.TopK), nil
+}
+
+// topKLabels 按分数降序取前 k 个标签，k<=0 时返回全部
+func topKLabels(scores []float32, labels []string, k int) []LabelScore {
+	result := make([]LabelScore, len(scores))
+	for i, score := range scores {
+		result[i] = LabelScore{Label: labels[i], Index: i, Score: score}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+
+	if k > 0 && k < len(result) {
+		result = result[:k]
+	}
+	return result
+}
+
+// imageToNormalizedFloat32 图像转 CHW 浮点数组，按 (x/255 - mean) / std 归一化
+func imageToNormalizedFloat32(img image.Image, width, height, channel int, mean, std []float32) []float32 {
+	data := make([]float32, channel*width*height)
+	bounds := img.Bounds()
+	plane := width * height
+
+	at := func(values []float32, c int) float32 {
+		if c < len(values) {
+			return values[c]
+		}
+		return values[len(values)-1]
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, _ := img.At(x+bounds.Min.X, y+bounds.Min.Y).RGBA()
+			idx := y*width + x
+
+			if channel == 1 {
+				gray := float32(0.299*float64(r>>8)+0.587*float64(g>>8)+0.114*float64(b>>8)) / 255
+				data[idx] = (gray - at(mean, 0)) / at(std, 0)
+				continue
+			}
+
+			for c, v := range [3]uint32{r >> 8, g >> 8, b >> 8} {
+				data[c*plane+idx] = (float32(v)/255 - at(mean, c)) / at(std, c)
+			}
+		}
+	}
+	return data
+}
@@ -0,0 +1,281 @@
+package ddddocr
+
+import (
+	"image"
+	"sort"
+)
+
+// ============================================================================
+// 二值化与连通域辅助函数
+// ============================================================================
+
+// component 连通域
+type component struct {
+	Box    BBox    // 外接框，X2/Y2 为开区间
+	Area   int     // 像素数
+	CX, CY float64 // 质心（图像坐标）
+}
+
+// grayPixels 图像转灰度字节数组
+func grayPixels(img image.Image) ([]uint8, int, int) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	gray := make([]uint8, w*h)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, b, _ := img.At(x+bounds.Min.X, y+bounds.Min.Y).RGBA()
+			gray[y*w+x] = uint8(0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8))
+		}
+	}
+	return gray, w, h
+}
+
+// otsuThreshold 计算 Otsu 阈值
+func otsuThreshold(gray []uint8) uint8 {
+	var hist [256]int
+	for _, v := range gray {
+		hist[v]++
+	}
+
+	total := len(gray)
+	var sum float64
+	for i, c := range hist {
+		sum += float64(i * c)
+	}
+
+	var sumB, maxVar float64
+	var wB int
+	threshold := uint8(0)
+	for i, c := range hist {
+		wB += c
+		if wB == 0 {
+			continue
+		}
+		wF := total - wB
+		if wF == 0 {
+			break
+		}
+		sumB += float64(i * c)
+		mB := sumB / float64(wB)
+		mF := (sum - sumB) / float64(wF)
+		between := float64(wB) * float64(wF) * (mB - mF) * (mB - mF)
+		if between > maxVar {
+			maxVar = between
+			threshold = uint8(i)
+		}
+	}
+	return threshold
+}
+
+// foregroundMask Otsu 二值化，以边框像素的多数一侧作为背景
+func foregroundMask(gray []uint8, w, h int) []bool {
+	threshold := otsuThreshold(gray)
+
+	bright := 0
+	border := 0
+	for x := 0; x < w; x++ {
+		for _, y := range []int{0, h - 1} {
+			if gray[y*w+x] > threshold {
+				bright++
+			}
+			border++
+		}
+	}
+	for y := 0; y < h; y++ {
+		for _, x := range []int{0, w - 1} {
+			if gray[y*w+x] > threshold {
+				bright++
+			}
+			border++
+		}
+	}
+	darkForeground := bright*2 >= border
+
+	mask := make([]bool, w*h)
+	for i, v := range gray {
+		if darkForeground {
+			mask[i] = v <= threshold
+		} else {
+			mask[i] = v > threshold
+		}
+	}
+	return mask
+}
+
+// dilateMask 方形结构元素膨胀
+func dilateMask(mask []bool, w, h, radius int) []bool {
+	if radius <= 0 {
+		return mask
+	}
+
+	// 先水平后垂直，等价于 (2r+1)x(2r+1) 方形膨胀
+	tmp := make([]bool, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			for dx := -radius; dx <= radius; dx++ {
+				nx := x + dx
+				if nx >= 0 && nx < w && mask[y*w+nx] {
+					tmp[y*w+x] = true
+					break
+				}
+			}
+		}
+	}
+
+	result := make([]bool, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			for dy := -radius; dy <= radius; dy++ {
+				ny := y + dy
+				if ny >= 0 && ny < h && tmp[ny*w+x] {
+					result[y*w+x] = true
+					break
+				}
+			}
+		}
+	}
+	return result
+}
+
+// connectedComponents 8 邻域连通域标记，按面积降序返回
+func connectedComponents(mask []bool, w, h int) []component {
+	_, components := labelComponents(mask, w, h)
+	sort.SliceStable(components, func(i, j int) bool {
+		return components[i].Area > components[j].Area
+	})
+	return components
+}
+
+// labelComponents 8 邻域连通域标记
+//
+// 返回每个像素所属连通域的下标（背景为 -1）及按下标排列的连通域。
+func labelComponents(mask []bool, w, h int) ([]int, []component) {
+	labels := make([]int, w*h)
+	for i := range labels {
+		labels[i] = -1
+	}
+	var components []component
+	stack := make([]int, 0, 64)
+
+	for start, fg := range mask {
+		if !fg || labels[start] >= 0 {
+			continue
+		}
+
+		label := len(components)
+		comp := component{Box: BBox{X1: w, Y1: h}}
+		var sumX, sumY float64
+		labels[start] = label
+		stack = append(stack[:0], start)
+
+		for len(stack) > 0 {
+			idx := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y := idx%w, idx/w
+
+			comp.Area++
+			sumX += float64(x)
+			sumY += float64(y)
+			comp.Box.X1 = min(comp.Box.X1, x)
+			comp.Box.Y1 = min(comp.Box.Y1, y)
+			comp.Box.X2 = max(comp.Box.X2, x+1)
+			comp.Box.Y2 = max(comp.Box.Y2, y+1)
+
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= w || ny >= h {
+						continue
+					}
+					n := ny*w + nx
+					if mask[n] && labels[n] < 0 {
+						labels[n] = label
+						stack = append(stack, n)
+					}
+				}
+			}
+		}
+
+		comp.CX = sumX / float64(comp.Area)
+		comp.CY = sumY / float64(comp.Area)
+		components = append(components, comp)
+	}
+
+	return labels, components
+}
+
+// erodeMask 方形结构元素腐蚀（图像外视为前景）
+func erodeMask(mask []bool, w, h, radius int) []bool {
+	if radius <= 0 {
+		return mask
+	}
+
+	inverted := make([]bool, len(mask))
+	for i, m := range mask {
+		inverted[i] = !m
+	}
+	dilated := dilateMask(inverted, w, h, radius)
+
+	result := make([]bool, len(mask))
+	for i, m := range dilated {
+		result[i] = !m
+	}
+	return result
+}
+
+// openMask 开运算：先腐蚀后膨胀，去除小噪点与细连接
+func openMask(mask []bool, w, h, radius int) []bool {
+	return dilateMask(erodeMask(mask, w, h, radius), w, h, radius)
+}
+
+// closeMask 闭运算：先膨胀后腐蚀，填补小空洞与断裂
+func closeMask(mask []bool, w, h, radius int) []bool {
+	return erodeMask(dilateMask(mask, w, h, radius), w, h, radius)
+}
+
+// fillHoles 填充前景包围的背景区域（从边框出发 4 邻域不可达的背景像素）
+func fillHoles(mask []bool, w, h int) []bool {
+	outside := make([]bool, w*h)
+	stack := make([]int, 0, 64)
+	push := func(x, y int) {
+		i := y*w + x
+		if !mask[i] && !outside[i] {
+			outside[i] = true
+			stack = append(stack, i)
+		}
+	}
+
+	for x := 0; x < w; x++ {
+		push(x, 0)
+		push(x, h-1)
+	}
+	for y := 0; y < h; y++ {
+		push(0, y)
+		push(w-1, y)
+	}
+
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		x, y := i%w, i/w
+		if x > 0 {
+			push(x-1, y)
+		}
+		if x < w-1 {
+			push(x+1, y)
+		}
+		if y > 0 {
+			push(x, y-1)
+		}
+		if y < h-1 {
+			push(x, y+1)
+		}
+	}
+
+	result := make([]bool, w*h)
+	for i := range result {
+		result[i] = mask[i] || !outside[i]
+	}
+	return result
+}
@@ -0,0 +1,177 @@
+package ddddocr
+
+import (
+	"math"
+	"testing"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+var ctcTestCharsets = []string{"", "a", "b", "c"}
+
+// ctcLogits 按布局生成 logits 输出，batch 0 为 seq，其余 batch 全部预测字符 c
+func ctcLogits(layout string, seq []int, batch int) ([]float32, ort.Shape) {
+	numClasses := len(ctcTestCharsets)
+	timesteps := len(seq)
+	var shape ort.Shape
+	switch layout {
+	case OutputLayoutTC:
+		shape = ort.NewShape(int64(timesteps), int64(numClasses))
+	case OutputLayoutBTC:
+		shape = ort.NewShape(int64(batch), int64(timesteps), int64(numClasses))
+	default:
+		shape = ort.NewShape(int64(timesteps), int64(batch), int64(numClasses))
+	}
+
+	data := make([]float32, timesteps*batch*numClasses)
+	for b := 0; b < batch; b++ {
+		for t, idx := range seq {
+			if b > 0 {
+				idx = 3
+			}
+			offset := (t*batch + b) * numClasses
+			if layout == OutputLayoutBTC {
+				offset = (b*timesteps + t) * numClasses
+			}
+			for c := 0; c < numClasses; c++ {
+				data[offset+c] = -2
+			}
+			data[offset+idx] = 3
+		}
+	}
+	return data, shape
+}
+
+func TestDecodeOutputFloatLayouts(t *testing.T) {
+	tests := []struct {
+		name      string
+		gen       string // 生成数据的实际布局
+		batch     int
+		layout    string // 传给解码的布局
+		seq       []int
+		want      string
+		wantSteps int
+	}{
+		{"TBC 自动", OutputLayoutTBC, 1, OutputLayoutAuto, []int{1, 1, 0, 2}, "ab", 4},
+		{"TBC 多 batch 只取第一个", OutputLayoutTBC, 2, OutputLayoutAuto, []int{1, 1, 0, 2}, "ab", 4},
+		{"BTC 自动", OutputLayoutBTC, 1, OutputLayoutAuto, []int{1, 0, 1, 2}, "aab", 4},
+		{"BTC 显式多 batch", OutputLayoutBTC, 2, OutputLayoutBTC, []int{2, 2, 1}, "ba", 3},
+		{"TC", OutputLayoutTC, 1, OutputLayoutAuto, []int{0, 3, 3, 0, 1}, "ca", 5},
+		{"TBC 单时间步", OutputLayoutTBC, 1, OutputLayoutAuto, []int{3}, "c", 1},
+		{"BTC 单时间步", OutputLayoutBTC, 1, OutputLayoutAuto, []int{2}, "b", 1},
+		{"TBC 单时间步按声明布局", OutputLayoutTBC, 1, declaredCTCLayout(ort.NewShape(-1, 1, 4)), []int{2}, "b", 1},
+		{"BTC 单时间步按声明布局", OutputLayoutBTC, 1, declaredCTCLayout(ort.NewShape(1, -1, 4)), []int{2}, "b", 1},
+		{"TBC 单时间步多 batch 显式布局", OutputLayoutTBC, 2, OutputLayoutTBC, []int{1}, "a", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, shape := ctcLogits(tt.gen, tt.seq, tt.batch)
+			got := decodeOutputFloatFast(data, shape, tt.layout, ctcTestCharsets, len(ctcTestCharsets), nil)
+			if got != tt.want {
+				t.Fatalf("shape %v 布局 %q 解码为 %q, want %q", shape, tt.layout, got, tt.want)
+			}
+			if steps := len(ctcProbability(data, shape, tt.layout, OutputActivationAuto)); steps != tt.wantSteps {
+				t.Fatalf("shape %v 时间步 %d, want %d", shape, steps, tt.wantSteps)
+			}
+		})
+	}
+}
+
+func TestDeclaredCTCLayout(t *testing.T) {
+	tests := []struct {
+		dims ort.Shape
+		want string
+	}{
+		{ort.NewShape(-1, 1, 4), OutputLayoutTBC},
+		{ort.NewShape(40, 1, 4), OutputLayoutTBC},
+		{ort.NewShape(-1, 2, 4), OutputLayoutAuto},
+		{ort.NewShape(1, -1, 4), OutputLayoutBTC},
+		{ort.NewShape(1, 40, 4), OutputLayoutBTC},
+		{ort.NewShape(-1, -1, 4), OutputLayoutAuto},
+		{ort.NewShape(1, 1, 4), OutputLayoutAuto},
+		{ort.NewShape(-1, 1), OutputLayoutTBC},
+		{ort.NewShape(1, -1), OutputLayoutBTC},
+		{ort.NewShape(-1), OutputLayoutAuto},
+	}
+
+	for _, tt := range tests {
+		if got := declaredCTCLayout(tt.dims); got != tt.want {
+			t.Fatalf("declaredCTCLayout(%v) = %q, want %q", tt.dims, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeOutputIndices(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []int64
+		shape   ort.Shape
+		layout  string
+		allowed []int
+		want    string
+	}{
+		{"一维", []int64{1, 1, 0, 2, 2, 3}, ort.NewShape(6), OutputLayoutAuto, nil, "abc"},
+		{"BT 自动", []int64{1, 0, 1, 3}, ort.NewShape(1, 4), OutputLayoutAuto, nil, "aac"},
+		{"TB 自动", []int64{2, 2, 0, 1}, ort.NewShape(4, 1), OutputLayoutAuto, nil, "ba"},
+		{"TB 多 batch 只取第一个", []int64{1, 3, 1, 3, 2, 3}, ort.NewShape(3, 2), OutputLayoutTBC, nil, "ab"},
+		{"BT 多 batch 只取第一个", []int64{3, 0, 3, 1, 1, 1}, ort.NewShape(2, 3), OutputLayoutBTC, nil, "cc"},
+		{"单时间步", []int64{2}, ort.NewShape(1, 1), OutputLayoutAuto, nil, "b"},
+		{"字符范围", []int64{1, 2, 3}, ort.NewShape(3), OutputLayoutAuto, []int{1, 3}, "ac"},
+		{"越界索引忽略", []int64{-1, 1, 9, 2}, ort.NewShape(4), OutputLayoutAuto, nil, "ab"},
+		{"不支持的维度", []int64{1}, ort.NewShape(1, 1, 1), OutputLayoutAuto, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeOutputIndices(tt.data, tt.shape, tt.layout, ctcTestCharsets, tt.allowed); got != tt.want {
+				t.Fatalf("解码为 %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectCTCActivation(t *testing.T) {
+	logits := []float32{2, -1, 0.5, 3, 0, 1, -4, 0.2}
+	probs := []float32{0.7, 0.1, 0.1, 0.1, 0.25, 0.25, 0.25, 0.25}
+	logProbs := make([]float32, len(probs))
+	for i, v := range probs {
+		logProbs[i] = float32(math.Log(float64(v)))
+	}
+
+	tests := []struct {
+		name   string
+		output []float32
+		want   string
+	}{
+		{"logits", logits, OutputActivationLogits},
+		{"softmax", probs, OutputActivationSoftmax},
+		{"log_softmax", logProbs, OutputActivationLogSoftmax},
+		{"全为负数但不是对数概率", []float32{-1, -2, -3, -4, -1, -1, -1, -1}, OutputActivationLogits},
+		{"在 [0,1] 内但和不为 1", []float32{0.5, 0.5, 0.5, 0, 0.1, 0.2, 0.3, 0.4}, OutputActivationLogits},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectCTCActivation(tt.output, 2, 4, 4); got != tt.want {
+				t.Fatalf("detectCTCActivation = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// 时间步之间隔着其他 batch 时只检查 batch 0 的帧
+	interleaved := append(append(append([]float32{}, probs[:4]...), logits[:4]...), probs[4:]...)
+	interleaved = append(interleaved, logits[4:]...)
+	if got := detectCTCActivation(interleaved, 2, 4, 8); got != OutputActivationSoftmax {
+		t.Fatalf("TBC 多 batch 激活类型 %q, want %q", got, OutputActivationSoftmax)
+	}
+
+	probability := ctcProbability(logProbs, ort.NewShape(2, 4), OutputLayoutTC, OutputActivationAuto)
+	for step, frame := range probability {
+		for c, p := range frame {
+			if math.Abs(float64(p-probs[step*4+c])) > 1e-5 {
+				t.Fatalf("log_softmax 还原概率 [%d][%d] = %v, want %v", step, c, p, probs[step*4+c])
+			}
+		}
+	}
+}
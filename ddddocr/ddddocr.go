@@ -40,6 +40,22 @@ const (
 	RangeNonAlphaNum     = 7 // 除去字母数字的其他字符
 )
 
+// 自定义模型 CTC 输出布局
+const (
+	OutputLayoutAuto = ""    // 自动检测
+	OutputLayoutTBC  = "TBC" // [T, B, C]，ddddocr 默认布局
+	OutputLayoutBTC  = "BTC" // [B, T, C]，batch-first
+	OutputLayoutTC   = "TC"  // [T, C]
+)
+
+// 自定义模型 CTC 输出激活类型
+const (
+	OutputActivationAuto       = ""            // 自动检测
+	OutputActivationLogits     = "logits"      // 未归一化的 logits
+	OutputActivationSoftmax    = "softmax"     // 已经过 softmax 的概率
+	OutputActivationLogSoftmax = "log_softmax" // log-softmax 对数概率
+)
+
 // 模型下载配置
 const (
 	GitHubRepo = "yangbin1322/go-ddddocr"
@@ -223,20 +239,42 @@ type DdddOcr struct {
 	// 字符映射
 	charIndexMap   map[string]int
 	allowedIndices []int
+	ranges         interface{} // SetRanges 的参数，延迟加载时在 OCR 模型加载后解析
 
-	// 模式标识
+	// 模式标识（表示对应模型已加载，可同时存在）
 	isDetMode bool
 	isOcrMode bool
 
+	// 初始化选项，延迟加载时使用
+	opts   Options
+	loadMu sync.Mutex
+
 	// 自定义模型配置
 	useImportOnnx bool
 	word          bool
 	resizeConfig  []int // [width, height], -1 表示自适应
 	channel       int
 
+	// 输出格式
+	outputLayout     string
+	outputActivation string
+
 	// 检测模型
 	detSession *ort.DynamicAdvancedSession
 	detOptions *ort.SessionOptions
+	detConfig  DetectionConfig
+
+	// 图像分类模型
+	isClsMode  bool
+	clsSession *ort.DynamicAdvancedSession
+	clsOptions *ort.SessionOptions
+	clsConfig  classifierConfig
+
+	// 图像嵌入模型
+	isEmbMode  bool
+	embSession *ort.DynamicAdvancedSession
+	embOptions *ort.SessionOptions
+	embConfig  embeddingConfig
 }
 
 // ClassificationResult 概率输出结果
@@ -251,16 +289,95 @@ type BBox struct {
 	X1, Y1, X2, Y2 int
 }
 
+// Detection 带分数与类别的检测结果
+type Detection struct {
+	Box       BBox    `json:"box"`
+	Score     float32 `json:"score"`
+	ClassID   int     `json:"class_id"`
+	ClassName string  `json:"class_name,omitempty"`
+	Views     int     `json:"views,omitempty"` // TTA 模式下检出该框的视图数
+
+	// 开启 Refine 时为框内前景像素的质心
+	InkX float64 `json:"ink_x,omitempty"`
+	InkY float64 `json:"ink_y,omitempty"`
+}
+
+// DetectionOptions 检测选项，零值表示使用模型配置
+type DetectionOptions struct {
+	ScoreThreshold float32 // 置信度阈值
+	IoUThreshold   float32 // NMS IoU 阈值
+	ClassAware     bool    // 按类别分别做 NMS，默认类别无关
+	MaxDetections  int     // 最大返回数量，0 表示不限制
+	SoftNMS        bool    // 使用高斯 Soft-NMS，适合字符密集的点选验证码
+	SoftNMSSigma   float32 // Soft-NMS 高斯 sigma，默认 0.5
+
+	TileSize    int // 分块推理的块边长（像素），0 表示不分块
+	TileOverlap int // 相邻块的重叠像素，须小于 TileSize，建议大于最大目标尺寸
+
+	TTAScales []float64 // 测试时增强的缩放比例，如 {0.8, 1, 1.25}
+	TTAFlip   bool      // 测试时增强是否加入水平翻转
+	TTAFusion string    // 多视图融合方式: TTAFusionWBF（默认）或 TTAFusionNMS
+
+	Refine        bool          // 将检测框贴合到前景像素并计算墨迹质心
+	RefineOptions RefineOptions // 精修选项
+}
+
+// SlideMatchOptions 滑块匹配选项
+type SlideMatchOptions struct {
+	SimpleTarget bool // 滑块图不含透明通道，直接整图匹配
+	TopN         int  // 返回前 N 个互不重叠的候选位置，0 表示不返回
+
+	Method string        // 匹配方法: SlideMethodEdge（默认）、SlideMethodGray、SlideMethodSqDiff、SlideMethodGradient、SlideMethodVote
+	Canny  *CannyOptions // 边缘匹配使用的 Canny 选项，nil 表示 DefaultCannyOptions()
+	Mask   bool          // 用滑块透明通道作掩码，只比较滑块轮廓与内部像素（仅透明滑块有效）
+
+	Drag DragOptions // 计算 DragDistance 使用的渲染尺寸与滑块初始偏移
+
+	SearchRegion BBox // 搜索区域（背景图坐标），零值表示整张背景图
+	MinX         int  // 滑块左边缘的最小 x，用于跳过滑块起始槽位
+	YBand        bool // 只在 TargetY ± YTolerance 的行内搜索（仅透明滑块有效）
+	YTolerance   int  // YBand 的上下容差（像素）
+}
+
+// SlideCandidate 滑块候选位置
+type SlideCandidate struct {
+	Target []int   `json:"target"` // [x1, y1, x2, y2]
+	Score  float64 `json:"score"`  // 匹配得分，越大越好
+}
+
 // SlideMatchResult 滑块匹配结果
 type SlideMatchResult struct {
-	TargetX int   `json:"target_x"`
-	TargetY int   `json:"target_y"`
-	Target  []int `json:"target"` // [x1, y1, x2, y2]
+	TargetX    int              `json:"target_x"`
+	TargetY    int              `json:"target_y"`
+	Target     []int            `json:"target"`               // [x1, y1, x2, y2]
+	Score      float64          `json:"score"`                // 最佳位置的匹配得分（NCC 方法为 [-1, 1]）
+	Margin     float64          `json:"margin"`               // 最佳得分与次优（不重叠）候选得分之差，无次优候选时按 -1 计算
+	Candidates []SlideCandidate `json:"candidates,omitempty"` // 前 N 个互不重叠的候选，按得分降序
+
+	DragDistance float64 `json:"drag_distance"` // 滑块拖动距离（CSS 像素），见 SlideMatchOptions.Drag
+}
+
+// SlideComparisonOptions 滑块比较选项
+type SlideComparisonOptions struct {
+	Threshold   int // 差异阈值，任一通道差值大于该值视为不同，0 表示 80
+	BlurSize    int // 差分前的高斯模糊核尺寸（奇数），0 表示不模糊，用于抑制 JPEG 噪声
+	MorphRadius int // 差异二值图先开运算去噪、再闭运算补洞的半径，0 表示不处理
+
+	Align      bool // 差分前用相位相关估计两图的平移并对齐
+	AlignScale bool // 配准时同时估计缩放比例（两图尺寸比的 ±10%），需开启 Align
+
+	Drag DragOptions // 计算 DragDistance 使用的渲染尺寸与滑块初始偏移
 }
 
 // SlideComparisonResult 滑块比较结果
 type SlideComparisonResult struct {
-	Target []int `json:"target"` // [x, y]
+	Target       []int   `json:"target"`        // [x, y]，为兼容旧版本保留的逐列扫描结果（与 Python 版本一致，x 额外加 2），不参与 DragDistance
+	Box          []int   `json:"box"`           // 最大差异连通域的外接框 [x1, y1, x2, y2]，无差异时为空
+	Area         int     `json:"area"`          // 最大差异连通域的像素数
+	Confidence   float64 `json:"confidence"`    // 置信度 [0, 1]
+	DragDistance float64 `json:"drag_distance"` // 按 Box 左边缘换算的拖动距离（CSS 像素，无差异时缺口 x 取 0），见 SlideComparisonOptions.Drag
+
+	Transform *SlideTransform `json:"transform,omitempty"` // 开启 Align 时的配准结果
 }
 
 // ============================================================================
@@ -301,6 +418,82 @@ type Options struct {
 	CharsetsPath   string // 自定义字符集路径
 	ModelDir       string // 模型目录
 	AutoDownload   bool   // 自动下载模型（默认 true）
+	LazyLoad       bool   // 延迟加载：New 不加载模型，已启用的能力在首次使用时加载
+
+	OutputLayout     string // 自定义模型输出布局，留空自动检测
+	OutputActivation string // 自定义模型输出激活类型，留空自动检测
+
+	ClassifierPath       string // 图像分类模型路径
+	ClassifierConfigPath string // 图像分类配置路径（标签与预处理）
+
+	EmbeddingPath       string // 图像嵌入（Siamese）模型路径
+	EmbeddingConfigPath string // 图像嵌入预处理配置路径，可选
+
+	DetModelPath  string // 自定义 YOLOX 检测模型路径，留空使用 common_det.onnx
+	DetConfigPath string // 自定义检测模型配置路径，可选
+
+	customConfig *customModelConfig // 已解析的自定义模型配置（训练项目导入），优先于 CharsetsPath
+}
+
+// DetectionConfig YOLOX 检测模型配置
+type DetectionConfig struct {
+	InputSize      [2]int   `json:"input_size"`      // [height, width]
+	Strides        []int    `json:"strides"`         // 特征层步长
+	ClassNames     []string `json:"class_names"`     // 类别名称，可为空
+	NMSThreshold   float32  `json:"nms_threshold"`   // NMS IoU 阈值
+	ScoreThreshold float32  `json:"score_threshold"` // 置信度阈值
+	PadValue       float32  `json:"pad_value"`       // letterbox 填充值
+}
+
+// DefaultDetectionConfig common_det.onnx 的默认配置
+func DefaultDetectionConfig() DetectionConfig {
+	return DetectionConfig{
+		InputSize:      [2]int{416, 416},
+		Strides:        []int{8, 16, 32},
+		NMSThreshold:   0.45,
+		ScoreThreshold: 0.1,
+		PadValue:       114,
+	}
+}
+
+// loadDetectionConfig 读取检测配置，未设置的字段使用默认值
+func loadDetectionConfig(path string) (DetectionConfig, error) {
+	config := DefaultDetectionConfig()
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("读取检测配置失败: %w", err)
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("解析检测配置失败: %w", err)
+	}
+
+	if config.InputSize[0] <= 0 || config.InputSize[1] <= 0 {
+		return config, fmt.Errorf("检测配置 input_size 必须为正数")
+	}
+	if len(config.Strides) == 0 {
+		return config, fmt.Errorf("检测配置 strides 不能为空")
+	}
+	for _, stride := range config.Strides {
+		if stride <= 0 || config.InputSize[0]%stride != 0 || config.InputSize[1]%stride != 0 {
+			return config, fmt.Errorf("input_size %v 不能被步长 %d 整除", config.InputSize, stride)
+		}
+	}
+	return config, nil
+}
+
+// customModelConfig 自定义模型的字符集配置
+type customModelConfig struct {
+	Charset []string `json:"charset"`
+	Word    bool     `json:"word"`
+	Image   []int    `json:"image"`
+	Channel int      `json:"channel"`
+
+	OutputLayout     string `json:"output_layout"`
+	OutputActivation string `json:"output_activation"`
 }
 
 // DefaultOptions 默认选项
@@ -317,59 +510,180 @@ func DefaultOptions() Options {
 }
 
 // New 创建识别器
+//
+// 默认立即加载所有启用的能力（OCR、目标检测、自定义模型可以任意组合）；
+// LazyLoad 为 true 时不加载任何模型，已启用的能力在首次使用时按需加载，
+// 未启用的能力（如 Det=false）调用时返回模式错误。
 func New(opts Options) (*DdddOcr, error) {
-	// 设置默认模型目录
-	if opts.ModelDir == "" {
-		opts.ModelDir = DefaultModelDir
+	if err := prepareEnvironment(&opts); err != nil {
+		return nil, err
 	}
 
-	// 自动下载模型
-	if opts.AutoDownload && opts.ImportOnnxPath == "" {
-		if err := EnsureModels(opts.ModelDir); err != nil {
-			return nil, fmt.Errorf("模型下载失败: %w", err)
+	d := &DdddOcr{
+		charIndexMap: make(map[string]int),
+		channel:      1,
+		opts:         opts,
+	}
+
+	if opts.LazyLoad {
+		return d, nil
+	}
+
+	// OCR 模式（自定义模型优先）
+	if ocrEnabled(opts) {
+		if err := d.loadOcr(); err != nil {
+			d.Close()
+			return nil, err
 		}
 	}
 
-	// 设置 ONNX Runtime 路径
-	if onnxRuntimePath == "" {
-		SetOnnxRuntimePath(filepath.Join(opts.ModelDir, getOnnxRuntimeFileName()))
+	// 目标检测模式
+	if detEnabled(opts) {
+		if _, err := d.initDetectionMode(opts); err != nil {
+			d.Close()
+			return nil, err
+		}
 	}
 
-	if err := initOnnxRuntime(); err != nil {
-		return nil, fmt.Errorf("初始化 ONNX Runtime 失败: %w", err)
+	// 图像分类模式
+	if opts.ClassifierPath != "" {
+		if _, err := d.initClassifierMode(opts); err != nil {
+			d.Close()
+			return nil, err
+		}
 	}
 
-	d := &DdddOcr{
-		charIndexMap: make(map[string]int),
-		channel:      1,
+	// 图像嵌入模式
+	if opts.EmbeddingPath != "" {
+		if _, err := d.initEmbeddingMode(opts); err != nil {
+			d.Close()
+			return nil, err
+		}
+	}
+
+	// 未启用任何模型时为滑块模式 (无需加载模型)
+	return d, nil
+}
+
+// ocrEnabled 判断是否启用 OCR，设置了 ImportOnnxPath 时总是启用自定义 OCR
+func ocrEnabled(opts Options) bool {
+	return opts.Ocr || opts.ImportOnnxPath != ""
+}
+
+// detEnabled 判断是否启用目标检测
+func detEnabled(opts Options) bool {
+	return opts.Det || opts.DetModelPath != ""
+}
+
+// loadOcr 加载 OCR 模型，设置了 ImportOnnxPath 时加载自定义模型
+func (d *DdddOcr) loadOcr() error {
+	var err error
+	if d.opts.ImportOnnxPath != "" {
+		_, err = d.initCustomModel(d.opts)
+	} else {
+		_, err = d.initOcrMode(d.opts)
+	}
+	if err != nil {
+		return err
 	}
 
-	// 自定义模型模式
-	if opts.ImportOnnxPath != "" {
-		return d.initCustomModel(opts)
+	// 加载前设置的字符范围在字符集可用后才能解析
+	d.applyRanges()
+	return nil
+}
+
+// ensureOcr 确保 OCR 模型可用，延迟加载模式下首次使用时加载
+func (d *DdddOcr) ensureOcr() error {
+	d.loadMu.Lock()
+	defer d.loadMu.Unlock()
+
+	if d.isOcrMode {
+		return nil
+	}
+	if !d.opts.LazyLoad || !ocrEnabled(d.opts) {
+		return d.checkOcrMode()
 	}
+	return d.loadOcr()
+}
 
-	// 目标检测模式
-	if opts.Det {
-		return d.initDetectionMode(opts)
+// ensureDet 确保检测模型可用，延迟加载模式下首次使用时加载
+func (d *DdddOcr) ensureDet() error {
+	d.loadMu.Lock()
+	defer d.loadMu.Unlock()
+
+	if d.isDetMode {
+		return nil
+	}
+	if !d.opts.LazyLoad || !detEnabled(d.opts) {
+		return fmt.Errorf("当前不是目标检测模式，请使用 Det=true 初始化")
 	}
+	_, err := d.initDetectionMode(d.opts)
+	return err
+}
+
+// ensureCls 确保分类模型可用，延迟加载模式下首次使用时加载
+func (d *DdddOcr) ensureCls() error {
+	d.loadMu.Lock()
+	defer d.loadMu.Unlock()
 
-	// OCR 模式
-	if opts.Ocr {
-		return d.initOcrMode(opts)
+	if d.isClsMode {
+		return nil
+	}
+	if !d.opts.LazyLoad || d.opts.ClassifierPath == "" {
+		return fmt.Errorf("当前不是图像分类模式，请设置 ClassifierPath 初始化")
 	}
+	_, err := d.initClassifierMode(d.opts)
+	return err
+}
 
-	// 滑块模式 (无需加载模型)
-	d.isOcrMode = false
-	d.isDetMode = false
-	return d, nil
+// ensureEmb 确保嵌入模型可用，延迟加载模式下首次使用时加载
+func (d *DdddOcr) ensureEmb() error {
+	d.loadMu.Lock()
+	defer d.loadMu.Unlock()
+
+	if d.isEmbMode {
+		return nil
+	}
+	if !d.opts.LazyLoad || d.opts.EmbeddingPath == "" {
+		return fmt.Errorf("当前不是图像嵌入模式，请设置 EmbeddingPath 初始化")
+	}
+	_, err := d.initEmbeddingMode(d.opts)
+	return err
+}
+
+// prepareEnvironment 补全默认选项、下载模型并初始化 ONNX Runtime
+func prepareEnvironment(opts *Options) error {
+	// 设置默认模型目录
+	if opts.ModelDir == "" {
+		opts.ModelDir = DefaultModelDir
+	}
+
+	// 自动下载模型（仅使用自定义模型时无需下载内置模型）
+	usesBuiltin := (opts.ImportOnnxPath == "" && ocrEnabled(*opts)) ||
+		(opts.DetModelPath == "" && opts.Det)
+	hasCustom := opts.ImportOnnxPath != "" || opts.DetModelPath != "" ||
+		opts.ClassifierPath != "" || opts.EmbeddingPath != ""
+	if opts.AutoDownload && (usesBuiltin || !hasCustom) {
+		if err := EnsureModels(opts.ModelDir); err != nil {
+			return fmt.Errorf("模型下载失败: %w", err)
+		}
+	}
+
+	// 设置 ONNX Runtime 路径
+	if onnxRuntimePath == "" {
+		SetOnnxRuntimePath(filepath.Join(opts.ModelDir, getOnnxRuntimeFileName()))
+	}
+
+	if err := initOnnxRuntime(); err != nil {
+		return fmt.Errorf("初始化 ONNX Runtime 失败: %w", err)
+	}
+	return nil
 }
 
 // initOcrMode 初始化 OCR 模式
+//
+// 会话与字符集全部就绪后才设置 isOcrMode，加载失败时可以重试。
 func (d *DdddOcr) initOcrMode(opts Options) (*DdddOcr, error) {
-	d.isOcrMode = true
-	d.isDetMode = false
-
 	// 选择模型
 	var modelName string
 	if opts.Beta {
@@ -377,36 +691,39 @@ func (d *DdddOcr) initOcrMode(opts Options) (*DdddOcr, error) {
 	} else {
 		modelName = "common_old.onnx"
 	}
-	d.modelPath = filepath.Join(opts.ModelDir, modelName)
+	modelPath := filepath.Join(opts.ModelDir, modelName)
 
 	// 加载模型信息
-	inputs, outputs, err := ort.GetInputOutputInfo(d.modelPath)
+	inputs, outputs, err := ort.GetInputOutputInfo(modelPath)
 	if err != nil {
 		return nil, fmt.Errorf("获取模型信息失败: %w", err)
 	}
 
 	// 创建会话选项
-	d.options, err = ort.NewSessionOptions()
+	options, err := ort.NewSessionOptions()
 	if err != nil {
 		return nil, err
 	}
 
-	_ = d.options.SetIntraOpNumThreads(1)
-	_ = d.options.SetInterOpNumThreads(1)
-	_ = d.options.SetGraphOptimizationLevel(ort.GraphOptimizationLevel(99))
+	_ = options.SetIntraOpNumThreads(1)
+	_ = options.SetInterOpNumThreads(1)
+	_ = options.SetGraphOptimizationLevel(ort.GraphOptimizationLevel(99))
 
 	// 创建会话
-	d.session, err = ort.NewDynamicAdvancedSession(
-		d.modelPath,
+	session, err := ort.NewDynamicAdvancedSession(
+		modelPath,
 		[]string{inputs[0].Name},
 		[]string{outputs[0].Name},
-		d.options,
+		options,
 	)
 	if err != nil {
-		d.options.Destroy()
+		options.Destroy()
 		return nil, err
 	}
 
+	d.modelPath = modelPath
+	d.options = options
+	d.session = session
 	d.inputName = inputs[0].Name
 	d.outputName = outputs[0].Name
 
@@ -419,50 +736,39 @@ func (d *DdddOcr) initOcrMode(opts Options) (*DdddOcr, error) {
 		d.charIndexMap[c] = i
 	}
 
+	d.isOcrMode = true
 	return d, nil
 }
 
 // initDetectionMode 初始化检测模式
 func (d *DdddOcr) initDetectionMode(opts Options) (*DdddOcr, error) {
-	d.isOcrMode = false
-	d.isDetMode = true
-
 	modelPath := filepath.Join(opts.ModelDir, "common_det.onnx")
-
-	inputs, outputs, err := ort.GetInputOutputInfo(modelPath)
-	if err != nil {
-		return nil, fmt.Errorf("获取检测模型信息失败: %w", err)
+	if opts.DetModelPath != "" {
+		modelPath = opts.DetModelPath
 	}
 
-	d.detOptions, err = ort.NewSessionOptions()
+	config, err := loadDetectionConfig(opts.DetConfigPath)
 	if err != nil {
 		return nil, err
 	}
 
-	_ = d.detOptions.SetIntraOpNumThreads(1)
-	_ = d.detOptions.SetInterOpNumThreads(1)
-	_ = d.detOptions.SetGraphOptimizationLevel(ort.GraphOptimizationLevel(99))
-
-	d.detSession, err = ort.NewDynamicAdvancedSession(
-		modelPath,
-		[]string{inputs[0].Name},
-		[]string{outputs[0].Name},
-		d.detOptions,
-	)
+	session, options, err := newModelSession(modelPath)
 	if err != nil {
-		d.detOptions.Destroy()
-		return nil, err
+		return nil, fmt.Errorf("加载检测模型失败: %w", err)
 	}
 
+	d.detConfig = config
+	d.detSession = session
+	d.detOptions = options
+	d.isDetMode = true
 	return d, nil
 }
 
 // initCustomModel 初始化自定义模型
 func (d *DdddOcr) initCustomModel(opts Options) (*DdddOcr, error) {
-	d.useImportOnnx = true
-	d.isOcrMode = true
-	d.isDetMode = false
-	d.modelPath = opts.ImportOnnxPath
+	if opts.customConfig != nil {
+		return d.initCustomModelWithConfig(opts, *opts.customConfig)
+	}
 
 	// 加载字符集配置
 	if opts.CharsetsPath == "" {
@@ -474,59 +780,122 @@ func (d *DdddOcr) initCustomModel(opts Options) (*DdddOcr, error) {
 		return nil, fmt.Errorf("读取字符集文件失败: %w", err)
 	}
 
-	var config struct {
-		Charset []string `json:"charset"`
-		Word    bool     `json:"word"`
-		Image   []int    `json:"image"`
-		Channel int      `json:"channel"`
-	}
+	var config customModelConfig
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("解析字符集配置失败: %w", err)
 	}
 
-	d.charsets = config.Charset
-	d.charsetLen = len(d.charsets)
-	d.word = config.Word
-	d.resizeConfig = config.Image
-	d.channel = config.Channel
+	return d.initCustomModelWithConfig(opts, config)
+}
 
-	// 构建映射
-	for i, c := range d.charsets {
-		d.charIndexMap[c] = i
+// initCustomModelWithConfig 使用已解析的配置初始化自定义模型
+func (d *DdddOcr) initCustomModelWithConfig(opts Options, config customModelConfig) (*DdddOcr, error) {
+	// 选项优先于配置文件
+	outputLayout := strings.ToUpper(config.OutputLayout)
+	if opts.OutputLayout != "" {
+		outputLayout = strings.ToUpper(opts.OutputLayout)
+	}
+	outputActivation := strings.ToLower(config.OutputActivation)
+	if opts.OutputActivation != "" {
+		outputActivation = strings.ToLower(opts.OutputActivation)
+	}
+
+	switch outputLayout {
+	case OutputLayoutAuto, OutputLayoutTBC, OutputLayoutBTC, OutputLayoutTC:
+	default:
+		return nil, fmt.Errorf("不支持的输出布局: %s", outputLayout)
+	}
+	switch outputActivation {
+	case OutputActivationAuto, OutputActivationLogits, OutputActivationSoftmax, OutputActivationLogSoftmax:
+	default:
+		return nil, fmt.Errorf("不支持的输出激活类型: %s", outputActivation)
 	}
 
 	// 加载模型
-	inputs, outputs, err := ort.GetInputOutputInfo(d.modelPath)
+	inputs, outputs, err := ort.GetInputOutputInfo(opts.ImportOnnxPath)
 	if err != nil {
 		return nil, fmt.Errorf("获取模型信息失败: %w", err)
 	}
 
-	d.options, err = ort.NewSessionOptions()
+	options, err := ort.NewSessionOptions()
 	if err != nil {
 		return nil, err
 	}
 
-	_ = d.options.SetIntraOpNumThreads(1)
-	_ = d.options.SetInterOpNumThreads(1)
-	_ = d.options.SetGraphOptimizationLevel(ort.GraphOptimizationLevel(99))
+	_ = options.SetIntraOpNumThreads(1)
+	_ = options.SetInterOpNumThreads(1)
+	_ = options.SetGraphOptimizationLevel(ort.GraphOptimizationLevel(99))
 
-	d.session, err = ort.NewDynamicAdvancedSession(
-		d.modelPath,
+	session, err := ort.NewDynamicAdvancedSession(
+		opts.ImportOnnxPath,
 		[]string{inputs[0].Name},
 		[]string{outputs[0].Name},
-		d.options,
+		options,
 	)
 	if err != nil {
-		d.options.Destroy()
+		options.Destroy()
 		return nil, err
 	}
 
+	d.useImportOnnx = true
+	d.modelPath = opts.ImportOnnxPath
+	d.options = options
+	d.session = session
 	d.inputName = inputs[0].Name
 	d.outputName = outputs[0].Name
 
+	d.charsets = config.Charset
+	d.charsetLen = len(d.charsets)
+	d.word = config.Word
+	d.resizeConfig = config.Image
+	if config.Channel > 0 {
+		d.channel = config.Channel
+	}
+	if outputLayout == OutputLayoutAuto {
+		outputLayout = declaredCTCLayout(outputs[0].Dimensions)
+	}
+	d.outputLayout = outputLayout
+	d.outputActivation = outputActivation
+
+	// 构建映射
+	for i, c := range d.charsets {
+		d.charIndexMap[c] = i
+	}
+
+	d.isOcrMode = true
 	return d, nil
 }
 
+// newModelSession 创建单输入单输出的推理会话
+func newModelSession(modelPath string) (*ort.DynamicAdvancedSession, *ort.SessionOptions, error) {
+	inputs, outputs, err := ort.GetInputOutputInfo(modelPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取模型信息失败: %w", err)
+	}
+
+	options, err := ort.NewSessionOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_ = options.SetIntraOpNumThreads(1)
+	_ = options.SetInterOpNumThreads(1)
+	_ = options.SetGraphOptimizationLevel(ort.GraphOptimizationLevel(99))
+
+	session, err := ort.NewDynamicAdvancedSession(
+		modelPath,
+		[]string{inputs[0].Name},
+		[]string{outputs[0].Name},
+		options,
+	)
+	if err != nil {
+		options.Destroy()
+		return nil, nil, err
+	}
+
+	return session, options, nil
+}
+
 // loadCharsetsForModel 加载字符集
 func (d *DdddOcr) loadCharsetsForModel(modelDir string, beta bool) []string {
 	var charsetFile string
@@ -568,10 +937,26 @@ func loadCharsets(path string) []string {
 // ============================================================================
 
 // SetRanges 设置识别范围
+//
+// 延迟加载模式下可以在 OCR 模型加载前调用，范围会在模型加载后解析。
 func (d *DdddOcr) SetRanges(val interface{}) {
+	d.loadMu.Lock()
+	defer d.loadMu.Unlock()
+
+	d.ranges = val
+	d.applyRanges()
+}
+
+// applyRanges 按当前字符集解析 SetRanges 设置的范围，OCR 模型未加载时暂不解析
+func (d *DdddOcr) applyRanges() {
+	if d.ranges == nil || !d.isOcrMode {
+		d.allowedIndices = nil
+		return
+	}
+
 	var chars string
 
-	switch v := val.(type) {
+	switch v := d.ranges.(type) {
 	case string:
 		chars = v
 	case int:
@@ -621,6 +1006,10 @@ func (d *DdddOcr) SetRanges(val interface{}) {
 
 // ClearRanges 清除字符范围限制
 func (d *DdddOcr) ClearRanges() {
+	d.loadMu.Lock()
+	defer d.loadMu.Unlock()
+
+	d.ranges = nil
 	d.allowedIndices = nil
 }
 
@@ -639,8 +1028,8 @@ func (d *DdddOcr) Classification(imageData []byte) (string, error) {
 
 // ClassificationWithOptions 带选项的识别
 func (d *DdddOcr) ClassificationWithOptions(imageData []byte, opts ClassificationOptions) (string, error) {
-	if d.isDetMode {
-		return "", fmt.Errorf("当前为目标检测模式，请使用 Detection 方法")
+	if err := d.ensureOcr(); err != nil {
+		return "", err
 	}
 
 	// 解码图片
@@ -659,6 +1048,33 @@ func (d *DdddOcr) ClassificationWithOptions(imageData []byte, opts Classificatio
 		img = filterByColors(img, opts.Colors, opts.ColorRanges)
 	}
 
+	output, err := d.runOcr(img)
+	if err != nil {
+		return "", err
+	}
+	defer output.Destroy()
+
+	return d.decodeOcrOutput(output)
+}
+
+// checkOcrMode 检查是否已加载 OCR 模型
+func (d *DdddOcr) checkOcrMode() error {
+	if d.isOcrMode {
+		return nil
+	}
+	switch {
+	case d.isDetMode:
+		return fmt.Errorf("当前为目标检测模式，请使用 Detection 方法")
+	case d.isClsMode:
+		return fmt.Errorf("当前为图像分类模式，请使用 Classify 方法")
+	case d.isEmbMode:
+		return fmt.Errorf("当前为图像嵌入模式，请使用 Embed 方法")
+	}
+	return fmt.Errorf("当前未加载 OCR 模型，请使用 Ocr=true 或 LazyLoad 初始化")
+}
+
+// runOcr 按模型配置预处理图片并执行推理，调用方负责销毁返回的输出
+func (d *DdddOcr) runOcr(img image.Image) (ort.Value, error) {
 	// 计算缩放尺寸
 	var width, height int
 	bounds := img.Bounds()
@@ -708,30 +1124,38 @@ func (d *DdddOcr) ClassificationWithOptions(imageData []byte, opts Classificatio
 
 	inputTensor, err := ort.NewTensor(inputShape, inputData)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer inputTensor.Destroy()
 
 	outputs := []ort.Value{nil}
 	err = d.session.Run([]ort.Value{inputTensor}, outputs)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if outputs[0] == nil {
-		return "", fmt.Errorf("无输出")
+		return nil, fmt.Errorf("无输出")
 	}
-	defer outputs[0].Destroy()
+	return outputs[0], nil
+}
 
-	// 解码结果
-	if outputTensor, ok := outputs[0].(*ort.Tensor[float32]); ok {
+// decodeOcrOutput 按输出类型解码文本
+func (d *DdddOcr) decodeOcrOutput(output ort.Value) (string, error) {
+	switch t := output.(type) {
+	case *ort.Tensor[float32]:
 		return decodeOutputFloatFast(
-			outputTensor.GetData(),
-			outputTensor.GetShape(),
+			t.GetData(),
+			t.GetShape(),
+			d.outputLayout,
 			d.charsets,
 			d.charsetLen,
 			d.allowedIndices,
 		), nil
+	case *ort.Tensor[int64]:
+		return decodeOutputIndices(t.GetData(), t.GetShape(), d.outputLayout, d.charsets, d.allowedIndices), nil
+	case *ort.Tensor[int32]:
+		return decodeOutputIndices(int32ToInt64(t.GetData()), t.GetShape(), d.outputLayout, d.charsets, d.allowedIndices), nil
 	}
 
 	return "", fmt.Errorf("不支持的输出类型")
@@ -739,8 +1163,8 @@ func (d *DdddOcr) ClassificationWithOptions(imageData []byte, opts Classificatio
 
 // ClassificationProbability 获取概率输出
 func (d *DdddOcr) ClassificationProbability(imageData []byte) (*ClassificationResult, error) {
-	if d.isDetMode {
-		return nil, fmt.Errorf("当前为目标检测模式")
+	if err := d.ensureOcr(); err != nil {
+		return nil, err
 	}
 
 	img, _, err := image.Decode(bytes.NewReader(imageData))
@@ -748,99 +1172,253 @@ func (d *DdddOcr) ClassificationProbability(imageData []byte) (*ClassificationRe
 		return nil, fmt.Errorf("解码失败: %w", err)
 	}
 
-	bounds := img.Bounds()
-	dx := bounds.Dx()
-	dy := bounds.Dy()
-	width := int(float64(dx) * (64.0 / float64(dy)))
-	if width < 1 {
-		width = 1
+	output, err := d.runOcr(img)
+	if err != nil {
+		return nil, err
 	}
+	defer output.Destroy()
 
-	resizedImage := resize.Resize(uint(width), 64, img, resize.Bilinear)
-	inputData := imageToGrayFloat32(resizedImage, width, 64, false)
+	var probability [][]float32
+	switch t := output.(type) {
+	case *ort.Tensor[float32]:
+		probability = ctcProbability(t.GetData(), t.GetShape(), d.outputLayout, d.outputActivation)
+	case *ort.Tensor[int64]:
+		probability = oneHotProbability(t.GetData(), t.GetShape(), d.outputLayout, d.charsetLen)
+	case *ort.Tensor[int32]:
+		probability = oneHotProbability(int32ToInt64(t.GetData()), t.GetShape(), d.outputLayout, d.charsetLen)
+	default:
+		return nil, fmt.Errorf("不支持的输出类型")
+	}
 
-	inputShape := ort.NewShape(1, 1, 64, int64(width))
-	inputTensor, err := ort.NewTensor(inputShape, inputData)
+	// 解码文本
+	text, err := d.decodeOcrOutput(output)
 	if err != nil {
 		return nil, err
 	}
-	defer inputTensor.Destroy()
 
-	outputs := []ort.Value{nil}
-	err = d.session.Run([]ort.Value{inputTensor}, outputs)
+	// 根据是否有范围限制返回不同的字符集
+	var charsets []string
+	if len(d.allowedIndices) > 0 {
+		charsets = make([]string, len(d.allowedIndices))
+		for i, idx := range d.allowedIndices {
+			if idx < len(d.charsets) {
+				charsets[i] = d.charsets[idx]
+			}
+		}
+	} else {
+		charsets = d.charsets
+	}
+
+	return &ClassificationResult{
+		Text:        text,
+		Charsets:    charsets,
+		Probability: probability,
+	}, nil
+}
+
+// ============================================================================
+// 目标检测功能
+// ============================================================================
+
+// Detection 目标检测
+func (d *DdddOcr) Detection(imageData []byte) ([]BBox, error) {
+	detections, err := d.DetectionWithOptions(imageData, DetectionOptions{})
 	if err != nil {
 		return nil, err
 	}
 
-	if outputs[0] == nil {
-		return nil, fmt.Errorf("无输出")
+	bboxes := make([]BBox, len(detections))
+	for i, det := range detections {
+		bboxes[i] = det.Box
 	}
-	defer outputs[0].Destroy()
+	return bboxes, nil
+}
 
-	outputTensor, ok := outputs[0].(*ort.Tensor[float32])
-	if !ok {
-		return nil, fmt.Errorf("不支持的输出类型")
+// DetectionWithOptions 带选项的目标检测，返回分数与类别
+func (d *DdddOcr) DetectionWithOptions(imageData []byte, opts DetectionOptions) ([]Detection, error) {
+	if err := d.ensureDet(); err != nil {
+		return nil, err
 	}
 
-	data := outputTensor.GetData()
-	shape := outputTensor.GetShape()
+	// 解码图片
+	img, err := decodeImageCV(imageData)
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %w", err)
+	}
 
-	// 计算 softmax 概率
-	timesteps := int(shape[0])
-	numClasses := int(shape[2])
+	return d.detectImage(img, opts)
+}
 
-	probability := make([][]float32, timesteps)
-	for t := 0; t < timesteps; t++ {
-		offset := t * numClasses
-		probs := softmax(data[offset : offset+numClasses])
-		probability[t] = probs
+// detectImage 对已解码的图片执行检测与 NMS
+func (d *DdddOcr) detectImage(img image.Image, opts DetectionOptions) ([]Detection, error) {
+	opts = d.resolveDetectionOptions(opts)
+
+	var detections []Detection
+	if len(opts.TTAScales) > 0 || opts.TTAFlip {
+		var err error
+		if detections, err = d.detectTTA(img, opts); err != nil {
+			return nil, err
+		}
+	} else {
+		candidates, err := d.viewCandidates(img, opts)
+		if err != nil {
+			return nil, err
+		}
+		detections = nmsDetections(candidates, opts)
 	}
 
-	// 解码文本
-	text := decodeOutputFloatFast(data, shape, d.charsets, d.charsetLen, d.allowedIndices)
+	if opts.Refine {
+		boxes := make([]BBox, len(detections))
+		for i, det := range detections {
+			boxes[i] = det.Box
+		}
+		for i, refined := range refineBoxes(img, boxes, opts.RefineOptions) {
+			detections[i].Box = refined.Box
+			detections[i].InkX = refined.InkX
+			detections[i].InkY = refined.InkY
+		}
+	}
+	return detections, nil
+}
 
-	// 根据是否有范围限制返回不同的字符集
-	var charsets []string
-	if len(d.allowedIndices) > 0 {
-		charsets = make([]string, len(d.allowedIndices))
-		for i, idx := range d.allowedIndices {
-			if idx < len(d.charsets) {
-				charsets[i] = d.charsets[idx]
+// viewCandidates 对单个视图执行（可能分块的）推理
+func (d *DdddOcr) viewCandidates(img image.Image, opts DetectionOptions) ([]Detection, error) {
+	if opts.TileSize > 0 {
+		return d.detectTiledCandidates(img, opts)
+	}
+	return d.detectCandidates(img, opts.ScoreThreshold)
+}
+
+// detectTiledCandidates 分块推理，框坐标映射回整图
+//
+// 贴着块内部接缝的截断框只有在相邻块内部完整包含它时才丢弃（相邻块会给出完整的框）；
+// 否则保留，由调用方的 NMS 与其他块的结果合并，避免跨接缝的目标在两侧都被丢弃。
+func (d *DdddOcr) detectTiledCandidates(img image.Image, opts DetectionOptions) ([]Detection, error) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	const edgeMargin = 2
+
+	tiles, err := tileRects(w, h, opts.TileSize, opts.TileOverlap)
+	if err != nil {
+		return nil, err
+	}
+
+	// interiors 为各块去掉内部接缝边距后的区域，落在其中的框不会被该块截断
+	interiors := make([]image.Rectangle, len(tiles))
+	for i, tile := range tiles {
+		interiors[i] = tile
+		if tile.Min.X > 0 {
+			interiors[i].Min.X += edgeMargin
+		}
+		if tile.Min.Y > 0 {
+			interiors[i].Min.Y += edgeMargin
+		}
+		if tile.Max.X < w {
+			interiors[i].Max.X -= edgeMargin
+		}
+		if tile.Max.Y < h {
+			interiors[i].Max.Y -= edgeMargin
+		}
+	}
+
+	var candidates []Detection
+	for i, tile := range tiles {
+		sub := cropImage(img, tile)
+		dets, err := d.detectCandidates(sub, opts.ScoreThreshold)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, det := range dets {
+			det.Box = BBox{
+				X1: det.Box.X1 + tile.Min.X,
+				Y1: det.Box.Y1 + tile.Min.Y,
+				X2: det.Box.X2 + tile.Min.X,
+				Y2: det.Box.Y2 + tile.Min.Y,
+			}
+			if !boxInRect(det.Box, interiors[i]) && tileContainsBox(interiors, i, det.Box) {
+				continue
+			}
+			candidates = append(candidates, det)
+		}
+	}
+	return candidates, nil
+}
+
+// boxInRect 判断框是否完全落在矩形内
+func boxInRect(box BBox, rect image.Rectangle) bool {
+	return box.X1 >= rect.Min.X && box.Y1 >= rect.Min.Y && box.X2 <= rect.Max.X && box.Y2 <= rect.Max.Y
+}
+
+// tileContainsBox 判断除第 skip 块以外是否有块的内部完整包含该框
+func tileContainsBox(interiors []image.Rectangle, skip int, box BBox) bool {
+	for i, rect := range interiors {
+		if i != skip && boxInRect(box, rect) {
+			return true
+		}
+	}
+	return false
+}
+
+// tileRects 生成覆盖 w×h 图像的重叠分块，最后一块与图像边缘对齐
+func tileRects(w, h, size, overlap int) ([]image.Rectangle, error) {
+	if overlap < 0 || overlap >= size {
+		return nil, fmt.Errorf("分块重叠 %d 必须在 [0, %d) 范围内", overlap, size)
+	}
+
+	starts := func(length int) []int {
+		if length <= size {
+			return []int{0}
+		}
+		step := size - overlap
+		var result []int
+		for s := 0; ; s += step {
+			if s+size >= length {
+				result = append(result, length-size)
+				break
 			}
+			result = append(result, s)
 		}
-	} else {
-		charsets = d.charsets
+		return result
 	}
 
-	return &ClassificationResult{
-		Text:        text,
-		Charsets:    charsets,
-		Probability: probability,
-	}, nil
+	var rects []image.Rectangle
+	for _, y := range starts(h) {
+		for _, x := range starts(w) {
+			rects = append(rects, image.Rect(x, y, min(x+size, w), min(y+size, h)))
+		}
+	}
+	return rects, nil
 }
 
-// ============================================================================
-// 目标检测功能
-// ============================================================================
+// cropImage 复制图片的一块区域，rect 相对于图片左上角
+func cropImage(img image.Image, rect image.Rectangle) *image.RGBA {
+	bounds := img.Bounds()
+	rect = rect.Add(bounds.Min).Intersect(bounds)
+	crop := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(crop, crop.Bounds(), img, rect.Min, draw.Src)
+	return crop
+}
 
-// Detection 目标检测
-func (d *DdddOcr) Detection(imageData []byte) ([]BBox, error) {
-	if !d.isDetMode {
-		return nil, fmt.Errorf("当前不是目标检测模式，请使用 Det=true 初始化")
+// resolveDetectionOptions 用模型配置补全未设置的阈值
+func (d *DdddOcr) resolveDetectionOptions(opts DetectionOptions) DetectionOptions {
+	if opts.ScoreThreshold <= 0 {
+		opts.ScoreThreshold = d.detConfig.ScoreThreshold
 	}
-
-	// 解码图片
-	img, err := decodeImageCV(imageData)
-	if err != nil {
-		return nil, fmt.Errorf("解码图片失败: %w", err)
+	if opts.IoUThreshold <= 0 {
+		opts.IoUThreshold = d.detConfig.NMSThreshold
 	}
+	return opts
+}
 
+// detectCandidates 执行检测推理，返回 NMS 前的候选框（原图坐标）
+func (d *DdddOcr) detectCandidates(img image.Image, scoreThr float32) ([]Detection, error) {
 	origH := img.Bounds().Dy()
 	origW := img.Bounds().Dx()
 
-	// 预处理：缩放到 416x416
-	inputSize := [2]int{416, 416}
-	preprocessed, ratio := preproc(img, inputSize)
+	// 预处理：letterbox 缩放到模型输入尺寸
+	inputSize := d.detConfig.InputSize
+	preprocessed, ratio := preproc(img, inputSize, d.detConfig.PadValue)
 
 	// 创建输入张量
 	inputShape := ort.NewShape(1, 3, int64(inputSize[0]), int64(inputSize[1]))
@@ -867,10 +1445,12 @@ func (d *DdddOcr) Detection(imageData []byte) ([]BBox, error) {
 	}
 
 	// 后处理
-	predictions := demoPostprocess(outputTensor.GetData(), inputSize)
-	bboxes := multiclassNMS(predictions, ratio, origW, origH, 0.45, 0.1)
+	predictions := demoPostprocess(outputTensor.GetData(), inputSize, d.detConfig.Strides)
+	if predictions == nil {
+		return nil, fmt.Errorf("模型输出与检测配置（input_size/strides）不匹配")
+	}
 
-	return bboxes, nil
+	return decodeDetections(predictions, ratio, origW, origH, scoreThr, d.detConfig.ClassNames), nil
 }
 
 // ============================================================================
@@ -879,16 +1459,25 @@ func (d *DdddOcr) Detection(imageData []byte) ([]BBox, error) {
 
 // SlideMatch 滑块匹配（边缘检测算法）
 func (d *DdddOcr) SlideMatch(targetBytes, backgroundBytes []byte, simpleTarget bool) (*SlideMatchResult, error) {
+	return d.SlideMatchWithOptions(targetBytes, backgroundBytes, SlideMatchOptions{SimpleTarget: simpleTarget})
+}
+
+// SlideMatchWithOptions 使用自定义选项进行滑块匹配
+//
+// 结果中的 Score 与 Margin 可用于判断匹配是否可信：Margin 很小时说明存在
+// 得分接近的干扰缺口，TopN > 0 时 Candidates 给出备选位置。
+func (d *DdddOcr) SlideMatchWithOptions(targetBytes, backgroundBytes []byte, opts SlideMatchOptions) (*SlideMatchResult, error) {
 	var target image.Image
 	var targetX, targetY int
 	var err error
 
-	if !simpleTarget {
+	if !opts.SimpleTarget {
 		// 提取透明区域
 		target, targetX, targetY, err = getTarget(targetBytes)
 		if err != nil {
 			// 回退到简单模式
-			return d.SlideMatch(targetBytes, backgroundBytes, true)
+			opts.SimpleTarget = true
+			return d.SlideMatchWithOptions(targetBytes, backgroundBytes, opts)
 		}
 	} else {
 		target, _, err = image.Decode(bytes.NewReader(targetBytes))
@@ -903,274 +1492,136 @@ func (d *DdddOcr) SlideMatch(targetBytes, backgroundBytes []byte, simpleTarget b
 		return nil, fmt.Errorf("解码背景图失败: %w", err)
 	}
 
-	// 1. Canny 边缘检测（返回灰度图）
-	targetEdge := cannyEdgeDetect(target, 100, 200)
-	bgEdge := cannyEdgeDetect(background, 100, 200)
-
-	// 2. 灰度转 RGB（与 Python 的 cv2.cvtColor(img, cv2.COLOR_GRAY2RGB) 对应）
-	targetRGB := grayToRGB(targetEdge)
-	bgRGB := grayToRGB(bgEdge)
-
-	// 3. 模板匹配 (TM_CCOEFF_NORMED) - 在 RGB 图像上进行
-	maxLoc := templateMatchRGB(bgRGB, targetRGB)
-
 	h := target.Bounds().Dy()
 	w := target.Bounds().Dx()
 
-	return &SlideMatchResult{
+	result := &SlideMatchResult{
 		TargetX: targetX,
 		TargetY: targetY,
-		Target:  []int{maxLoc.X, maxLoc.Y, maxLoc.X + w, maxLoc.Y + h},
-	}, nil
-}
-
-// cannyEdgeDetect Canny 边缘检测（模拟 cv2.Canny）
-func cannyEdgeDetect(img image.Image, lowThreshold, highThreshold int) *image.Gray {
-	bounds := img.Bounds()
-	w := bounds.Dx()
-	h := bounds.Dy()
-
-	// 转灰度
-	gray := make([][]int, h)
-	for y := 0; y < h; y++ {
-		gray[y] = make([]int, w)
-		for x := 0; x < w; x++ {
-			r, g, b, _ := img.At(x+bounds.Min.X, y+bounds.Min.Y).RGBA()
-			gray[y][x] = int(0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8))
-		}
-	}
-
-	// Sobel 算子计算梯度
-	gradX := make([][]int, h)
-	gradY := make([][]int, h)
-	magnitude := make([][]int, h)
-	for y := 0; y < h; y++ {
-		gradX[y] = make([]int, w)
-		gradY[y] = make([]int, w)
-		magnitude[y] = make([]int, w)
 	}
 
-	for y := 1; y < h-1; y++ {
-		for x := 1; x < w-1; x++ {
-			gx := -gray[y-1][x-1] - 2*gray[y][x-1] - gray[y+1][x-1] +
-				gray[y-1][x+1] + 2*gray[y][x+1] + gray[y+1][x+1]
-			gy := -gray[y-1][x-1] - 2*gray[y-1][x] - gray[y-1][x+1] +
-				gray[y+1][x-1] + 2*gray[y+1][x] + gray[y+1][x+1]
-
-			gradX[y][x] = gx
-			gradY[y][x] = gy
-			magnitude[y][x] = int(math.Sqrt(float64(gx*gx + gy*gy)))
+	// 只在搜索区域内匹配，得分图坐标需加上区域偏移
+	rect := slideSearchRect(opts, background.Bounds().Dx(), background.Bounds().Dy(), targetY, h)
+	if rect.Dx() < w || rect.Dy() < h {
+		// 未限制搜索区域时与旧版本一致：滑块大于背景图返回左上角，不报错
+		if opts.SearchRegion == (BBox{}) && opts.MinX <= 0 && !(opts.YBand && !opts.SimpleTarget) {
+			result.Target = []int{0, 0, w, h}
+			result.DragDistance = DragDistance(0, targetX, background.Bounds().Dx(), opts.Drag)
+			return result, nil
 		}
+		return nil, fmt.Errorf("搜索区域 %v 小于滑块尺寸 %dx%d", rect, w, h)
 	}
 
-	// 非极大值抑制
-	suppressed := make([][]int, h)
-	for y := 0; y < h; y++ {
-		suppressed[y] = make([]int, w)
+	scores, rw, rh, err := slideScoreMap(opts, target, background, rect)
+	if err != nil {
+		return nil, err
 	}
 
-	for y := 1; y < h-1; y++ {
-		for x := 1; x < w-1; x++ {
-			mag := magnitude[y][x]
-			if mag == 0 {
-				continue
-			}
-
-			gx := float64(gradX[y][x])
-			gy := float64(gradY[y][x])
-			angle := math.Atan2(gy, gx) * 180 / math.Pi
-			if angle < 0 {
-				angle += 180
-			}
-
-			var q, r int
-			if (angle >= 0 && angle < 22.5) || (angle >= 157.5 && angle <= 180) {
-				q = magnitude[y][x+1]
-				r = magnitude[y][x-1]
-			} else if angle >= 22.5 && angle < 67.5 {
-				q = magnitude[y-1][x+1]
-				r = magnitude[y+1][x-1]
-			} else if angle >= 67.5 && angle < 112.5 {
-				q = magnitude[y-1][x]
-				r = magnitude[y+1][x]
-			} else {
-				q = magnitude[y-1][x-1]
-				r = magnitude[y+1][x+1]
-			}
-
-			if mag >= q && mag >= r {
-				suppressed[y][x] = mag
-			}
-		}
+	// 模板无纹理时无法匹配，返回搜索区域左上角
+	bgW := background.Bounds().Dx()
+	result.Target = []int{rect.Min.X, rect.Min.Y, rect.Min.X + w, rect.Min.Y + h}
+	result.DragDistance = DragDistance(result.Target[0], targetX, bgW, opts.Drag)
+	if scores == nil {
+		return result, nil
 	}
 
-	// 双阈值 + 滞后阈值
-	result := image.NewGray(bounds)
-	edge := make([][]int, h)
-	for y := 0; y < h; y++ {
-		edge[y] = make([]int, w)
+	// 至少取两个候选以计算领先幅度
+	n := opts.TopN
+	if n < 2 {
+		n = 2
 	}
-
-	for y := 1; y < h-1; y++ {
-		for x := 1; x < w-1; x++ {
-			if suppressed[y][x] >= highThreshold {
-				edge[y][x] = 2
-				result.SetGray(x+bounds.Min.X, y+bounds.Min.Y, color.Gray{255})
-			} else if suppressed[y][x] >= lowThreshold {
-				edge[y][x] = 1
-			}
-		}
+	candidates := topScoreCandidates(scores, rw, rh, w, h, n)
+	if len(candidates) == 0 {
+		return result, nil
 	}
-
-	// 边缘连接
-	changed := true
-	for changed {
-		changed = false
-		for y := 1; y < h-1; y++ {
-			for x := 1; x < w-1; x++ {
-				if edge[y][x] == 1 {
-					for dy := -1; dy <= 1; dy++ {
-						for dx := -1; dx <= 1; dx++ {
-							if edge[y+dy][x+dx] == 2 {
-								edge[y][x] = 2
-								result.SetGray(x+bounds.Min.X, y+bounds.Min.Y, color.Gray{255})
-								changed = true
-								break
-							}
-						}
-						if edge[y][x] == 2 {
-							break
-						}
-					}
-				}
-			}
-		}
+	for i := range candidates {
+		t := candidates[i].Target
+		t[0], t[1], t[2], t[3] = t[0]+rect.Min.X, t[1]+rect.Min.Y, t[2]+rect.Min.X, t[3]+rect.Min.Y
 	}
 
-	return result
-}
-
-// grayToRGB 灰度图转 RGB
-func grayToRGB(gray *image.Gray) *image.RGBA {
-	bounds := gray.Bounds()
-	rgb := image.NewRGBA(bounds)
-
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			g := gray.GrayAt(x, y).Y
-			rgb.Set(x, y, color.RGBA{g, g, g, 255})
+	result.Target = candidates[0].Target
+	result.Score = candidates[0].Score
+	result.DragDistance = DragDistance(result.Target[0], targetX, bgW, opts.Drag)
+	if len(candidates) > 1 {
+		result.Margin = candidates[0].Score - candidates[1].Score
+	} else {
+		result.Margin = candidates[0].Score + 1
+	}
+	if opts.TopN > 0 {
+		if len(candidates) > opts.TopN {
+			candidates = candidates[:opts.TopN]
 		}
+		result.Candidates = candidates
 	}
 
-	return rgb
+	return result, nil
 }
 
-// templateMatchRGB 在 RGB 图像上进行模板匹配 (TM_CCOEFF_NORMED)
-func templateMatchRGB(background, template *image.RGBA) image.Point {
-	bgBounds := background.Bounds()
-	tplBounds := template.Bounds()
-
-	bgW := bgBounds.Dx()
-	bgH := bgBounds.Dy()
-	tplW := tplBounds.Dx()
-	tplH := tplBounds.Dy()
-
-	if tplW > bgW || tplH > bgH {
-		return image.Point{}
-	}
-
-	tplSize := tplW * tplH
+// slideSearchRect 根据选项计算背景图上的搜索区域
+func slideSearchRect(opts SlideMatchOptions, bgW, bgH, targetY, h int) image.Rectangle {
+	rect := image.Rect(0, 0, bgW, bgH)
 
-	tplR := make([]float64, tplSize)
-	tplG := make([]float64, tplSize)
-	tplB := make([]float64, tplSize)
-	var sumR, sumG, sumB float64
-
-	for y := 0; y < tplH; y++ {
-		for x := 0; x < tplW; x++ {
-			c := template.RGBAAt(x+tplBounds.Min.X, y+tplBounds.Min.Y)
-			idx := y*tplW + x
-			tplR[idx] = float64(c.R)
-			tplG[idx] = float64(c.G)
-			tplB[idx] = float64(c.B)
-			sumR += float64(c.R)
-			sumG += float64(c.G)
-			sumB += float64(c.B)
-		}
+	r := opts.SearchRegion
+	if r.X2 > r.X1 && r.Y2 > r.Y1 {
+		rect = rect.Intersect(image.Rect(r.X1, r.Y1, r.X2, r.Y2))
 	}
-
-	n := float64(tplSize)
-	meanR := sumR / n
-	meanG := sumG / n
-	meanB := sumB / n
-
-	var tplVarR, tplVarG, tplVarB float64
-	for i := 0; i < tplSize; i++ {
-		tplR[i] -= meanR
-		tplG[i] -= meanG
-		tplB[i] -= meanB
-		tplVarR += tplR[i] * tplR[i]
-		tplVarG += tplG[i] * tplG[i]
-		tplVarB += tplB[i] * tplB[i]
+	if opts.MinX > rect.Min.X {
+		rect.Min.X = min(opts.MinX, rect.Max.X)
 	}
-	tplStd := math.Sqrt(tplVarR + tplVarG + tplVarB)
-
-	if tplStd < 1.0 {
-		return image.Point{}
+	if opts.YBand && !opts.SimpleTarget {
+		tol := max(opts.YTolerance, 0)
+		band := image.Rect(rect.Min.X, targetY-tol, rect.Max.X, targetY+tol+h)
+		rect = rect.Intersect(band)
 	}
 
-	maxVal := float64(-2)
-	maxLoc := image.Point{}
-
-	for y := 0; y <= bgH-tplH; y++ {
-		for x := 0; x <= bgW-tplW; x++ {
-			var wSumR, wSumG, wSumB float64
-			for ty := 0; ty < tplH; ty++ {
-				for tx := 0; tx < tplW; tx++ {
-					c := background.RGBAAt(x+tx+bgBounds.Min.X, y+ty+bgBounds.Min.Y)
-					wSumR += float64(c.R)
-					wSumG += float64(c.G)
-					wSumB += float64(c.B)
-				}
-			}
-			wMeanR := wSumR / n
-			wMeanG := wSumG / n
-			wMeanB := wSumB / n
-
-			var crossSum, winVar float64
-			for ty := 0; ty < tplH; ty++ {
-				for tx := 0; tx < tplW; tx++ {
-					c := background.RGBAAt(x+tx+bgBounds.Min.X, y+ty+bgBounds.Min.Y)
-					idx := ty*tplW + tx
-
-					drW := float64(c.R) - wMeanR
-					dgW := float64(c.G) - wMeanG
-					dbW := float64(c.B) - wMeanB
-
-					crossSum += drW*tplR[idx] + dgW*tplG[idx] + dbW*tplB[idx]
-					winVar += drW*drW + dgW*dgW + dbW*dbW
-				}
-			}
+	return rect
+}
 
-			winStd := math.Sqrt(winVar)
-			if winStd < 1.0 {
+// topScoreCandidates 从得分图中依次取出互不重叠的最高分位置
+//
+// 每取出一个位置，就屏蔽与其模板框相交的所有位置；无效位置（-2）不参与。
+func topScoreCandidates(scores []float64, rw, rh, w, h, n int) []SlideCandidate {
+	const eps = 1e-9
+	suppressed := make([]bool, len(scores))
+	var candidates []SlideCandidate
+
+	for len(candidates) < n {
+		best := -1
+		for i, v := range scores {
+			if suppressed[i] || v <= -2 {
 				continue
 			}
+			if best < 0 || v > scores[best]+eps {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
 
-			ncc := crossSum / (winStd * tplStd)
+		bx, by := best%rw, best/rw
+		candidates = append(candidates, SlideCandidate{
+			Target: []int{bx, by, bx + w, by + h},
+			Score:  scores[best],
+		})
 
-			if ncc > maxVal {
-				maxVal = ncc
-				maxLoc = image.Point{X: x, Y: y}
+		for y := max(0, by-h+1); y < min(rh, by+h); y++ {
+			for x := max(0, bx-w+1); x < min(rw, bx+w); x++ {
+				suppressed[y*rw+x] = true
 			}
 		}
 	}
 
-	return maxLoc
+	return candidates
 }
 
 // SlideComparison 滑块比较（图像差异算法）
 func (d *DdddOcr) SlideComparison(targetBytes, backgroundBytes []byte) (*SlideComparisonResult, error) {
+	return d.SlideComparisonWithOptions(targetBytes, backgroundBytes, SlideComparisonOptions{})
+}
+
+// SlideComparisonWithOptions 使用自定义选项进行滑块比较
+func (d *DdddOcr) SlideComparisonWithOptions(targetBytes, backgroundBytes []byte, opts SlideComparisonOptions) (*SlideComparisonResult, error) {
 	target, _, err := image.Decode(bytes.NewReader(targetBytes))
 	if err != nil {
 		return nil, fmt.Errorf("解码目标图失败: %w", err)
@@ -1183,8 +1634,29 @@ func (d *DdddOcr) SlideComparison(targetBytes, backgroundBytes []byte) (*SlideCo
 
 	targetBounds := target.Bounds()
 	bgBounds := background.Bounds()
+	sameSize := targetBounds.Dx() == bgBounds.Dx() && targetBounds.Dy() == bgBounds.Dy()
+
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = 80
+	}
+	if opts.BlurSize < 0 || (opts.BlurSize > 0 && opts.BlurSize%2 == 0) {
+		return nil, fmt.Errorf("高斯核尺寸必须为正奇数: %d", opts.BlurSize)
+	}
 
-	if targetBounds.Dx() != bgBounds.Dx() || targetBounds.Dy() != bgBounds.Dy() {
+	// 配准直接使用原始背景图，warpToTarget 负责重采样到目标图坐标系；
+	// 已对齐（尺寸相同、位移不足半像素且无缩放）或峰值过低时不做变换
+	var transform *SlideTransform
+	if opts.Align {
+		t := estimateTransform(target, background, opts.AlignScale)
+		aligned := sameSize && math.Abs(t.DX) < 0.5 && math.Abs(t.DY) < 0.5 && t.Scale == 1
+		if !aligned && t.Peak >= minAlignPeak {
+			background = warpToTarget(background, target, t)
+			t.Applied = true
+		}
+		transform = &t
+	}
+	if !sameSize && (transform == nil || !transform.Applied) {
 		background = resize.Resize(
 			uint(targetBounds.Dx()),
 			uint(targetBounds.Dy()),
@@ -1193,117 +1665,72 @@ func (d *DdddOcr) SlideComparison(targetBytes, backgroundBytes []byte) (*SlideCo
 		)
 	}
 
-	targetRGB := toRGB(target)
-	backgroundRGB := toRGB(background)
-
-	diff := imageDifferenceRGB(backgroundRGB, targetRGB)
-
-	threshold := uint8(80)
-	binaryImg := binarizeRGB(diff, threshold)
-
-	startX, startY := findGapPython(binaryImg, 5)
-
-	return &SlideComparisonResult{
-		Target: []int{startX, startY},
-	}, nil
-}
-
-// toRGB 转换图像为 RGB 格式
-func toRGB(img image.Image) *image.RGBA {
-	bounds := img.Bounds()
-	rgba := image.NewRGBA(bounds)
-	draw.Draw(rgba, bounds, img, bounds.Min, draw.Src)
-	return rgba
-}
-
-// imageDifferenceRGB 计算两张 RGB 图像的差异
-func imageDifferenceRGB(img1, img2 image.Image) *image.RGBA {
-	bounds := img1.Bounds()
-	bounds2 := img2.Bounds()
-
-	w := bounds.Dx()
-	h := bounds.Dy()
-	if bounds2.Dx() < w {
-		w = bounds2.Dx()
+	diff, w, h := channelMaxDiff(background, target, opts.BlurSize)
+	mask := make([]bool, len(diff))
+	for i, v := range diff {
+		mask[i] = int(v) > threshold
 	}
-	if bounds2.Dy() < h {
-		h = bounds2.Dy()
+	if opts.MorphRadius > 0 {
+		mask = closeMask(openMask(mask, w, h, opts.MorphRadius), w, h, opts.MorphRadius)
 	}
 
-	result := image.NewRGBA(image.Rect(0, 0, w, h))
-
-	for y := 0; y < h; y++ {
-		for x := 0; x < w; x++ {
-			r1, g1, b1, _ := img1.At(x+bounds.Min.X, y+bounds.Min.Y).RGBA()
-			r2, g2, b2, _ := img2.At(x+bounds2.Min.X, y+bounds2.Min.Y).RGBA()
-
-			dr := absDiff(uint8(r1>>8), uint8(r2>>8))
-			dg := absDiff(uint8(g1>>8), uint8(g2>>8))
-			db := absDiff(uint8(b1>>8), uint8(b2>>8))
+	startX, startY := findGapColumn(mask, w, h, 5)
+	result := &SlideComparisonResult{
+		Target:    []int{startX, startY},
+		Transform: transform,
+	}
 
-			result.Set(x, y, color.RGBA{dr, dg, db, 255})
-		}
+	gapX := 0
+	if comp, confidence, ok := largestDiffComponent(mask, diff, w, h, threshold); ok {
+		result.Box = []int{comp.Box.X1, comp.Box.Y1, comp.Box.X2, comp.Box.Y2}
+		result.Area = comp.Area
+		result.Confidence = confidence
+		gapX = comp.Box.X1
 	}
+	result.DragDistance = DragDistance(gapX, 0, w, opts.Drag)
 
-	return result
+	return result, nil
 }
 
-func absDiff(a, b uint8) uint8 {
-	if a > b {
-		return a - b
+// channelMaxDiff 逐像素计算两图各通道差值的最大值，可先做高斯模糊
+func channelMaxDiff(img1, img2 image.Image, blurSize int) ([]uint8, int, int) {
+	w := min(img1.Bounds().Dx(), img2.Bounds().Dx())
+	h := min(img1.Bounds().Dy(), img2.Bounds().Dy())
+	rect := image.Rect(0, 0, w, h)
+
+	r1, g1, b1 := rgbPlanes(cropImage(img1, rect))
+	r2, g2, b2 := rgbPlanes(cropImage(img2, rect))
+	planes1 := [][]int32{r1, g1, b1}
+	planes2 := [][]int32{r2, g2, b2}
+	if blurSize > 1 {
+		for c := range planes1 {
+			planes1[c] = gaussianBlurPlane(planes1[c], w, h, blurSize, 0)
+			planes2[c] = gaussianBlurPlane(planes2[c], w, h, blurSize, 0)
+		}
 	}
-	return b - a
-}
-
-// binarizeRGB 对 RGB 图像进行二值化
-func binarizeRGB(img *image.RGBA, threshold uint8) *image.RGBA {
-	bounds := img.Bounds()
-	result := image.NewRGBA(bounds)
 
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			r, g, b, _ := img.At(x, y).RGBA()
-			r8, g8, b8 := uint8(r>>8), uint8(g>>8), uint8(b>>8)
-
-			if r8 > threshold {
-				r8 = 255
-			} else {
-				r8 = 0
-			}
-			if g8 > threshold {
-				g8 = 255
-			} else {
-				g8 = 0
+	diff := make([]uint8, w*h)
+	for c := range planes1 {
+		for i := range diff {
+			d := planes1[c][i] - planes2[c][i]
+			if d < 0 {
+				d = -d
 			}
-			if b8 > threshold {
-				b8 = 255
-			} else {
-				b8 = 0
-			}
-
-			result.Set(x, y, color.RGBA{r8, g8, b8, 255})
+			diff[i] = max(diff[i], uint8(d))
 		}
 	}
-
-	return result
+	return diff, w, h
 }
 
-// findGapPython 查找缺口位置
-func findGapPython(img image.Image, minCount int) (int, int) {
-	bounds := img.Bounds()
-	w := bounds.Dx()
-	h := bounds.Dy()
-
+// findGapColumn 逐列扫描，返回第一个差异像素数不少于 minCount 的列（与 Python 版本一致，x 额外加 2）
+func findGapColumn(mask []bool, w, h, minCount int) (int, int) {
 	startX := 0
 	startY := 0
 
 	for x := 0; x < w; x++ {
 		count := 0
 		for y := 0; y < h; y++ {
-			r, g, b, _ := img.At(x+bounds.Min.X, y+bounds.Min.Y).RGBA()
-			r8, g8, b8 := uint8(r>>8), uint8(g>>8), uint8(b>>8)
-
-			if r8 != 0 || g8 != 0 || b8 != 0 {
+			if mask[y*w+x] {
 				count++
 				if count == 1 && startY == 0 {
 					startY = y
@@ -1320,6 +1747,39 @@ func findGapPython(img image.Image, minCount int) (int, int) {
 	return startX, startY
 }
 
+// largestDiffComponent 选取面积最大的差异连通域并计算置信度
+//
+// 置信度综合该连通域占全部差异像素的比例、外接框填充率和平均差异强度。
+func largestDiffComponent(mask []bool, diff []uint8, w, h, threshold int) (component, float64, bool) {
+	labels, comps := labelComponents(mask, w, h)
+	if len(comps) == 0 {
+		return component{}, 0, false
+	}
+
+	best, total := 0, 0
+	for i, c := range comps {
+		total += c.Area
+		if c.Area > comps[best].Area {
+			best = i
+		}
+	}
+	comp := comps[best]
+
+	var diffSum float64
+	for i, l := range labels {
+		if l == best {
+			diffSum += float64(diff[i])
+		}
+	}
+
+	boxArea := (comp.Box.X2 - comp.Box.X1) * (comp.Box.Y2 - comp.Box.Y1)
+	dominance := float64(comp.Area) / float64(total)
+	fill := math.Min(1, float64(comp.Area)/float64(boxArea)/0.75)
+	contrast := math.Min(1, diffSum/float64(comp.Area)/float64(2*threshold))
+
+	return comp, 0.5*dominance + 0.25*fill + 0.25*contrast, true
+}
+
 // ============================================================================
 // 图像处理辅助函数
 // ============================================================================
@@ -1466,28 +1926,66 @@ func imageToRGBFloat32(img image.Image, width, height int) []float32 {
 	return data
 }
 
+// ctcOutputDims 计算 CTC 输出的时间步数、类别数和相邻时间步的偏移（只取 batch 0）
+func ctcOutputDims(shape ort.Shape, layout string) (timesteps, numClasses, frameStride int, ok bool) {
+	switch len(shape) {
+	case 2:
+		return int(shape[0]), int(shape[1]), int(shape[1]), true
+	case 3:
+		if layout != OutputLayoutTBC && layout != OutputLayoutBTC {
+			layout = detectCTCLayout(shape)
+		}
+		if layout == OutputLayoutBTC {
+			return int(shape[1]), int(shape[2]), int(shape[2]), true
+		}
+		return int(shape[0]), int(shape[2]), int(shape[1] * shape[2]), true
+	}
+	return 0, 0, 0, false
+}
+
+// detectCTCLayout 根据实际输出形状推测三维输出的布局
+//
+// 推理时 batch 恒为 1，TBC 输出的第二维必为 1，因此 [1, N>1, C] 只能是 BTC。
+// [1, 1, C] 两种读法都只有一个时间步，按 TBC 处理；模型声明了维度时由
+// declaredCTCLayout 在加载时确定布局，不经过这里。
+func detectCTCLayout(shape ort.Shape) string {
+	if shape[0] == 1 && shape[1] != 1 {
+		return OutputLayoutBTC
+	}
+	return OutputLayoutTBC
+}
+
+// declaredCTCLayout 根据模型声明的输出维度确定布局，无法确定时返回空字符串
+//
+// 固定为 1 的维度视为 batch，另一维为时间步（动态维度为 -1）。
+// 适用于三维概率输出和二维 argmax 输出。
+func declaredCTCLayout(dims ort.Shape) string {
+	if len(dims) != 2 && len(dims) != 3 {
+		return OutputLayoutAuto
+	}
+	switch {
+	case dims[1] == 1 && dims[0] != 1:
+		return OutputLayoutTBC
+	case dims[0] == 1 && dims[1] != 1:
+		return OutputLayoutBTC
+	}
+	return OutputLayoutAuto
+}
+
 // decodeOutputFloatFast 解码输出
-func decodeOutputFloatFast(output []float32, shape ort.Shape, charsets []string, charsetLen int, allowedIndices []int) string {
+func decodeOutputFloatFast(output []float32, shape ort.Shape, layout string, charsets []string, charsetLen int, allowedIndices []int) string {
 	var sb strings.Builder
 	sb.Grow(16)
 
-	var timesteps, batchSize, numClasses int
-	if len(shape) == 3 {
-		timesteps = int(shape[0])
-		batchSize = int(shape[1])
-		numClasses = int(shape[2])
-	} else if len(shape) == 2 {
-		timesteps = int(shape[0])
-		numClasses = int(shape[1])
-		batchSize = 1
-	} else {
+	timesteps, numClasses, frameStride, ok := ctcOutputDims(shape, layout)
+	if !ok {
 		return ""
 	}
 
 	lastIdx := -1
 
 	for t := 0; t < timesteps; t++ {
-		offset := t * batchSize * numClasses
+		offset := t * frameStride
 
 		maxIdx := 0
 		maxVal := float32(-1e9)
@@ -1521,6 +2019,139 @@ func decodeOutputFloatFast(output []float32, shape ort.Shape, charsets []string,
 	return sb.String()
 }
 
+// ctcIndexDims 计算预 argmax 整数输出的时间步数和步长（只取 batch 0）
+func ctcIndexDims(shape ort.Shape, layout string) (timesteps, stride int, ok bool) {
+	switch len(shape) {
+	case 1:
+		return int(shape[0]), 1, true
+	case 2:
+		batchFirst := layout == OutputLayoutBTC ||
+			(layout != OutputLayoutTBC && shape[0] == 1 && shape[1] != 1)
+		if batchFirst {
+			return int(shape[1]), 1, true
+		}
+		return int(shape[0]), int(shape[1]), true
+	}
+	return 0, 0, false
+}
+
+// decodeOutputIndices 解码已 argmax 的整数输出
+func decodeOutputIndices(output []int64, shape ort.Shape, layout string, charsets []string, allowedIndices []int) string {
+	var sb strings.Builder
+
+	timesteps, stride, ok := ctcIndexDims(shape, layout)
+	if !ok {
+		return ""
+	}
+
+	var allowed map[int]bool
+	if len(allowedIndices) > 0 {
+		allowed = make(map[int]bool, len(allowedIndices))
+		for _, idx := range allowedIndices {
+			allowed[idx] = true
+		}
+	}
+
+	lastIdx := -1
+	for t := 0; t < timesteps; t++ {
+		idx := int(output[t*stride])
+		if idx != lastIdx && idx > 0 && idx < len(charsets) && (allowed == nil || allowed[idx]) {
+			sb.WriteString(charsets[idx])
+		}
+		lastIdx = idx
+	}
+	return sb.String()
+}
+
+// detectCTCActivation 根据输出数值推测激活类型
+func detectCTCActivation(output []float32, timesteps, numClasses, frameStride int) string {
+	isProb, isLogProb := true, true
+	for t := 0; t < timesteps && (isProb || isLogProb); t++ {
+		frame := output[t*frameStride : t*frameStride+numClasses]
+		var sum, expSum float64
+		for _, v := range frame {
+			if v < 0 || v > 1 {
+				isProb = false
+			}
+			if v > 0 {
+				isLogProb = false
+			}
+			sum += float64(v)
+			expSum += math.Exp(float64(v))
+		}
+		if math.Abs(sum-1) > 1e-2 {
+			isProb = false
+		}
+		if math.Abs(expSum-1) > 1e-2 {
+			isLogProb = false
+		}
+	}
+
+	switch {
+	case isProb:
+		return OutputActivationSoftmax
+	case isLogProb:
+		return OutputActivationLogSoftmax
+	}
+	return OutputActivationLogits
+}
+
+// ctcProbability 将浮点输出转换为每个时间步的概率分布
+func ctcProbability(output []float32, shape ort.Shape, layout, activation string) [][]float32 {
+	timesteps, numClasses, frameStride, ok := ctcOutputDims(shape, layout)
+	if !ok {
+		return nil
+	}
+
+	if activation == OutputActivationAuto {
+		activation = detectCTCActivation(output, timesteps, numClasses, frameStride)
+	}
+
+	probability := make([][]float32, timesteps)
+	for t := 0; t < timesteps; t++ {
+		frame := output[t*frameStride : t*frameStride+numClasses]
+		switch activation {
+		case OutputActivationSoftmax:
+			probability[t] = append([]float32(nil), frame...)
+		case OutputActivationLogSoftmax:
+			probs := make([]float32, numClasses)
+			for c, v := range frame {
+				probs[c] = float32(math.Exp(float64(v)))
+			}
+			probability[t] = probs
+		default:
+			probability[t] = softmax(frame)
+		}
+	}
+	return probability
+}
+
+// oneHotProbability 将整数输出转换为 one-hot 概率分布
+func oneHotProbability(output []int64, shape ort.Shape, layout string, numClasses int) [][]float32 {
+	timesteps, stride, ok := ctcIndexDims(shape, layout)
+	if !ok {
+		return nil
+	}
+
+	probability := make([][]float32, timesteps)
+	for t := 0; t < timesteps; t++ {
+		probs := make([]float32, numClasses)
+		if idx := int(output[t*stride]); idx >= 0 && idx < numClasses {
+			probs[idx] = 1
+		}
+		probability[t] = probs
+	}
+	return probability
+}
+
+func int32ToInt64(data []int32) []int64 {
+	result := make([]int64, len(data))
+	for i, v := range data {
+		result[i] = int64(v)
+	}
+	return result
+}
+
 // softmax 计算 softmax
 func softmax(input []float32) []float32 {
 	output := make([]float32, len(input))
@@ -1559,7 +2190,7 @@ func decodeImageCV(data []byte) (image.Image, error) {
 	return rgba, nil
 }
 
-func preproc(img image.Image, inputSize [2]int) ([]float32, float64) {
+func preproc(img image.Image, inputSize [2]int, padValue float32) ([]float32, float64) {
 	bounds := img.Bounds()
 	origH := bounds.Dy()
 	origW := bounds.Dx()
@@ -1573,7 +2204,7 @@ func preproc(img image.Image, inputSize [2]int) ([]float32, float64) {
 
 	padded := make([]float32, 3*inputSize[0]*inputSize[1])
 	for i := range padded {
-		padded[i] = 114.0
+		padded[i] = padValue
 	}
 
 	resizedBounds := resized.Bounds()
@@ -1590,9 +2221,7 @@ func preproc(img image.Image, inputSize [2]int) ([]float32, float64) {
 	return padded, r
 }
 
-func demoPostprocess(outputs []float32, imgSize [2]int) [][]float32 {
-	strides := []int{8, 16, 32}
-
+func demoPostprocess(outputs []float32, imgSize [2]int, strides []int) [][]float32 {
 	var grids [][]float32
 	var expandedStrides []float32
 
@@ -1609,6 +2238,9 @@ func demoPostprocess(outputs []float32, imgSize [2]int) [][]float32 {
 	}
 
 	numAnchors := len(grids)
+	if numAnchors == 0 || len(outputs)%numAnchors != 0 || len(outputs)/numAnchors < 6 {
+		return nil
+	}
 	numClasses := len(outputs)/numAnchors - 5
 
 	predictions := make([][]float32, numAnchors)
@@ -1631,20 +2263,18 @@ func demoPostprocess(outputs []float32, imgSize [2]int) [][]float32 {
 	return predictions
 }
 
-func multiclassNMS(predictions [][]float32, ratio float64, origW, origH int, nmsThr, scoreThr float32) []BBox {
-	type detection struct {
-		box   BBox
-		score float32
-	}
-
-	var dets []detection
+// decodeDetections 将预测结果还原到原图坐标并按分数过滤
+func decodeDetections(predictions [][]float32, ratio float64, origW, origH int, scoreThr float32, classNames []string) []Detection {
+	var dets []Detection
 
 	for _, pred := range predictions {
 		objScore := pred[4]
 		maxClassScore := float32(0)
+		classID := 0
 		for j := 5; j < len(pred); j++ {
 			if pred[j] > maxClassScore {
 				maxClassScore = pred[j]
+				classID = j - 5
 			}
 		}
 		score := objScore * maxClassScore
@@ -1672,31 +2302,67 @@ func multiclassNMS(predictions [][]float32, ratio float64, origW, origH int, nms
 			y2 = origH
 		}
 
-		dets = append(dets, detection{
-			box:   BBox{X1: x1, Y1: y1, X2: x2, Y2: y2},
-			score: score,
-		})
+		det := Detection{
+			Box:     BBox{X1: x1, Y1: y1, X2: x2, Y2: y2},
+			Score:   score,
+			ClassID: classID,
+		}
+		if classID < len(classNames) {
+			det.ClassName = classNames[classID]
+		}
+		dets = append(dets, det)
 	}
 
-	sort.Slice(dets, func(i, j int) bool {
-		return dets[i].score > dets[j].score
+	return dets
+}
+
+// nmsDetections 非极大值抑制（硬 NMS 或高斯 Soft-NMS）
+func nmsDetections(dets []Detection, opts DetectionOptions) []Detection {
+	dets = append([]Detection(nil), dets...)
+	sort.SliceStable(dets, func(i, j int) bool {
+		return dets[i].Score > dets[j].Score
 	})
 
-	var result []BBox
+	sigma := opts.SoftNMSSigma
+	if sigma <= 0 {
+		sigma = 0.5
+	}
+
+	var result []Detection
 	used := make([]bool, len(dets))
 
-	for i := 0; i < len(dets); i++ {
-		if used[i] {
-			continue
+	for {
+		// 选出剩余分数最高的框（Soft-NMS 会改变分数顺序）
+		best := -1
+		for i := range dets {
+			if !used[i] && (best < 0 || dets[i].Score > dets[best].Score) {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
+		used[best] = true
+		result = append(result, dets[best])
+
+		if opts.MaxDetections > 0 && len(result) >= opts.MaxDetections {
+			break
 		}
-		result = append(result, dets[i].box)
 
-		for j := i + 1; j < len(dets); j++ {
+		for j := range dets {
 			if used[j] {
 				continue
 			}
-			iou := computeIoU(dets[i].box, dets[j].box)
-			if iou > nmsThr {
+			if opts.ClassAware && dets[j].ClassID != dets[best].ClassID {
+				continue
+			}
+			iou := computeIoU(dets[best].Box, dets[j].Box)
+			if opts.SoftNMS {
+				dets[j].Score *= float32(math.Exp(-float64(iou*iou) / float64(sigma)))
+				if dets[j].Score < opts.ScoreThreshold {
+					used[j] = true
+				}
+			} else if iou > opts.IoUThreshold {
 				used[j] = true
 			}
 		}
@@ -1818,5 +2484,17 @@ func (d *DdddOcr) Close() error {
 	if d.detOptions != nil {
 		d.detOptions.Destroy()
 	}
+	if d.clsSession != nil {
+		d.clsSession.Destroy()
+	}
+	if d.clsOptions != nil {
+		d.clsOptions.Destroy()
+	}
+	if d.embSession != nil {
+		d.embSession.Destroy()
+	}
+	if d.embOptions != nil {
+		d.embOptions.Destroy()
+	}
 	return nil
 }
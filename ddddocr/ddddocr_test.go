@@ -0,0 +1,145 @@
+package ddddocr
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newLazyTestOcr 构造延迟加载的识别器，不经过 New 以避免下载模型和初始化 ONNX Runtime
+func newLazyTestOcr(opts Options) *DdddOcr {
+	opts.LazyLoad = true
+	return &DdddOcr{
+		charIndexMap: make(map[string]int),
+		channel:      1,
+		opts:         opts,
+	}
+}
+
+// testPNG 编码一张纯色小图
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 16, 16))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLazyLoadFailureIsRetried(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.onnx")
+	charsets := writeTestFile(t, dir, "charsets.json", `{"charset": ["", "a", "b"], "image": [-1, 64]}`)
+	clsConfig := writeTestFile(t, dir, "cls.json", `{"labels": ["a", "b"]}`)
+	imageData := testPNG(t)
+
+	tests := []struct {
+		name string
+		opts Options
+		call func(d *DdddOcr) error
+	}{
+		{
+			name: "ocr",
+			opts: Options{Ocr: true, ModelDir: dir},
+			call: func(d *DdddOcr) error { _, err := d.Classification(imageData); return err },
+		},
+		{
+			name: "custom",
+			opts: Options{ImportOnnxPath: missing, CharsetsPath: charsets},
+			call: func(d *DdddOcr) error { _, err := d.Classification(imageData); return err },
+		},
+		{
+			name: "detection",
+			opts: Options{Det: true, ModelDir: dir},
+			call: func(d *DdddOcr) error { _, err := d.Detection(imageData); return err },
+		},
+		{
+			name: "classifier",
+			opts: Options{ClassifierPath: missing, ClassifierConfigPath: clsConfig},
+			call: func(d *DdddOcr) error { _, err := d.Classify(imageData); return err },
+		},
+		{
+			name: "embedding",
+			opts: Options{EmbeddingPath: missing},
+			call: func(d *DdddOcr) error { _, err := d.Embed(imageData); return err },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newLazyTestOcr(tt.opts)
+			for i := 0; i < 2; i++ {
+				if err := tt.call(d); err == nil {
+					t.Fatalf("第 %d 次调用应返回错误", i+1)
+				}
+			}
+			if d.isOcrMode || d.isDetMode || d.isClsMode || d.isEmbMode {
+				t.Fatalf("加载失败后不应设置模式标识")
+			}
+		})
+	}
+}
+
+func TestLazyLoadDisabledCapability(t *testing.T) {
+	d := newLazyTestOcr(Options{Ocr: true, Det: false})
+	if _, err := d.Detection(testPNG(t)); err == nil {
+		t.Fatal("Det=false 时检测应返回模式错误")
+	}
+
+	d = newLazyTestOcr(Options{Ocr: false, Det: true})
+	if _, err := d.Classification(testPNG(t)); err == nil {
+		t.Fatal("Ocr=false 时识别应返回模式错误")
+	}
+
+	// Ocr 与 Det 相互独立：Det=true 不关闭 OCR，调用时尝试加载模型而不是返回模式错误
+	d = newLazyTestOcr(Options{Ocr: true, Det: true, ModelDir: t.TempDir()})
+	_, err := d.Classification(testPNG(t))
+	if err == nil || strings.Contains(err.Error(), "模式") {
+		t.Fatalf("Det=true 且 Ocr=true 时不应返回模式错误: %v", err)
+	}
+}
+
+func TestSetRangesBeforeLazyLoad(t *testing.T) {
+	d := newLazyTestOcr(Options{Ocr: true})
+	d.SetRanges(RangeDigit)
+	if d.allowedIndices != nil {
+		t.Fatalf("模型加载前不应解析范围: %v", d.allowedIndices)
+	}
+
+	// 模拟 loadOcr 成功后的状态
+	d.charsets = []string{"", "a", "1", "2"}
+	for i, c := range d.charsets {
+		d.charIndexMap[c] = i
+	}
+	d.isOcrMode = true
+	d.applyRanges()
+
+	want := []int{0, 2, 3}
+	if len(d.allowedIndices) != len(want) {
+		t.Fatalf("allowedIndices = %v, want %v", d.allowedIndices, want)
+	}
+	for i := range want {
+		if d.allowedIndices[i] != want[i] {
+			t.Fatalf("allowedIndices = %v, want %v", d.allowedIndices, want)
+		}
+	}
+
+	d.ClearRanges()
+	d.applyRanges()
+	if d.allowedIndices != nil {
+		t.Fatalf("ClearRanges 后不应再限制范围: %v", d.allowedIndices)
+	}
+}
@@ -0,0 +1,34 @@
+package ddddocr
+
+// ============================================================================
+// 拖动距离换算
+// ============================================================================
+
+// DragOptions 拖动距离换算选项
+//
+// 识别结果位于背景图原始像素坐标系，页面上的背景图通常经过缩放，
+// 拖动距离需要换算到 CSS 像素。
+type DragOptions struct {
+	RenderedWidth float64 // 背景图在页面上的渲染宽度（CSS 像素），> 0 时缩放比例 = RenderedWidth / 原图宽度
+	Scale         float64 // 渲染缩放比例，RenderedWidth 未设置时使用，均未设置时为 1
+	PieceOffset   float64 // 滑块图左边缘相对背景图左边缘的初始偏移（原图像素）
+}
+
+// scale 计算缩放比例
+func (o DragOptions) scale(naturalWidth int) float64 {
+	if o.RenderedWidth > 0 && naturalWidth > 0 {
+		return o.RenderedWidth / float64(naturalWidth)
+	}
+	if o.Scale > 0 {
+		return o.Scale
+	}
+	return 1
+}
+
+// DragDistance 计算拖动距离（CSS 像素）
+//
+// gapX 为缺口左边缘，pieceX 为滑块在滑块图内的左边缘（如 SlideMatchResult.TargetX），
+// naturalWidth 为背景图原始宽度。结果为 (gapX - pieceX - PieceOffset) × 缩放比例，不做取整。
+func DragDistance(gapX, pieceX, naturalWidth int, opts DragOptions) float64 {
+	return (float64(gapX-pieceX) - opts.PieceOffset) * opts.scale(naturalWidth)
+}
@@ -0,0 +1,57 @@
+package ddddocr
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestDragDistance(t *testing.T) {
+	tests := []struct {
+		name         string
+		gapX, pieceX int
+		naturalWidth int
+		opts         DragOptions
+		want         float64
+	}{
+		{"默认", 120, 10, 300, DragOptions{}, 110},
+		{"渲染宽度", 120, 10, 300, DragOptions{RenderedWidth: 340}, 110 * 340.0 / 300},
+		{"缩放比例", 120, 10, 300, DragOptions{Scale: 0.5}, 55},
+		{"渲染宽度优先于缩放比例", 120, 10, 300, DragOptions{RenderedWidth: 150, Scale: 2}, 55},
+		{"滑块初始偏移", 120, 10, 300, DragOptions{PieceOffset: 5}, 105},
+		{"偏移按原图像素计算后缩放", 120, 10, 300, DragOptions{RenderedWidth: 340, PieceOffset: 5}, 105 * 340.0 / 300},
+		{"原图宽度为 0 时退回缩放比例", 120, 10, 0, DragOptions{RenderedWidth: 340, Scale: 2}, 220},
+		{"原图宽度为 0 且未设置缩放", 120, 10, 0, DragOptions{RenderedWidth: 340}, 110},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DragDistance(tt.gapX, tt.pieceX, tt.naturalWidth, tt.opts)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("DragDistance = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlideComparisonDragDistanceUsesBox(t *testing.T) {
+	full := noisyBackground(300, 150, 7)
+	gap := image.NewGray(full.Rect)
+	copy(gap.Pix, full.Pix)
+	carveGap(gap, image.Rect(120, 40, 164, 84), 0)
+
+	d := &DdddOcr{}
+	result, err := d.SlideComparisonWithOptions(encodeTestPNG(t, gap), encodeTestPNG(t, full), SlideComparisonOptions{
+		Threshold: 30,
+		Drag:      DragOptions{RenderedWidth: 340},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Box) != 4 || result.Box[0] != 120 {
+		t.Fatalf("Box = %v, want x1 = 120", result.Box)
+	}
+	if want := 120 * 340.0 / 300; math.Abs(result.DragDistance-want) > 1e-9 {
+		t.Fatalf("DragDistance = %v, want %v", result.DragDistance, want)
+	}
+}
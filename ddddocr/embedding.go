@@ -0,0 +1,421 @@
+package ddddocr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"math"
+	"os"
+
+	"github.com/nfnt/resize"
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// ============================================================================
+// 图像嵌入与图标匹配
+// ============================================================================
+
+// IconMatch 图标匹配结果
+type IconMatch struct {
+	PromptIndex    int     `json:"prompt_index"`    // 提示图标序号
+	CandidateIndex int     `json:"candidate_index"` // 候选序号，-1 表示未匹配
+	Box            BBox    `json:"box"`             // 候选框（仅 MatchIconsInImage）
+	Score          float32 `json:"score"`           // 余弦相似度
+}
+
+// embeddingConfig 图像嵌入模型预处理配置
+type embeddingConfig struct {
+	Image   []int     `json:"image"` // [width, height]
+	Channel int       `json:"channel"`
+	Mean    []float32 `json:"mean"`
+	Std     []float32 `json:"std"`
+}
+
+// initEmbeddingMode 初始化图像嵌入模式
+func (d *DdddOcr) initEmbeddingMode(opts Options) (*DdddOcr, error) {
+	config := embeddingConfig{
+		Image:   []int{64, 64},
+		Channel: 3,
+	}
+	if opts.EmbeddingConfigPath != "" {
+		data, err := os.ReadFile(opts.EmbeddingConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("读取嵌入配置失败: %w", err)
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("解析嵌入配置失败: %w", err)
+		}
+	}
+	if len(config.Image) < 2 || config.Image[0] <= 0 || config.Image[1] <= 0 {
+		return nil, fmt.Errorf("嵌入配置 image 必须为 [width, height]")
+	}
+	if config.Channel != 1 && config.Channel != 3 {
+		return nil, fmt.Errorf("不支持的通道数: %d", config.Channel)
+	}
+	if len(config.Mean) == 0 {
+		config.Mean = []float32{0.5}
+	}
+	if len(config.Std) == 0 {
+		config.Std = []float32{0.5}
+	}
+	d.embConfig = config
+
+	var err error
+	d.embSession, d.embOptions, err = newModelSession(opts.EmbeddingPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载嵌入模型失败: %w", err)
+	}
+
+	d.isEmbMode = true
+	return d, nil
+}
+
+// Embed 计算图片的 L2 归一化嵌入向量
+func (d *DdddOcr) Embed(imageData []byte) ([]float32, error) {
+	img, _, err := image.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("解码失败: %w", err)
+	}
+	return d.embedImage(img)
+}
+
+// embedImage 对已解码的图片计算嵌入向量
+func (d *DdddOcr) embedImage(img image.Image) ([]float32, error) {
+	if err := d.ensureEmb(); err != nil {
+		return nil, err
+	}
+
+	config := d.embConfig
+	width, height := config.Image[0], config.Image[1]
+
+	resized := resize.Resize(uint(width), uint(height), img, resize.Bilinear)
+	inputData := imageToNormalizedFloat32(resized, width, height, config.Channel, config.Mean, config.Std)
+
+	inputShape := ort.NewShape(1, int64(config.Channel), int64(height), int64(width))
+	inputTensor, err := ort.NewTensor(inputShape, inputData)
+	if err != nil {
+		return nil, err
+	}
+	defer inputTensor.Destroy()
+
+	outputs := []ort.Value{nil}
+	err = d.embSession.Run([]ort.Value{inputTensor}, outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	if outputs[0] == nil {
+		return nil, fmt.Errorf("无输出")
+	}
+	defer outputs[0].Destroy()
+
+	outputTensor, ok := outputs[0].(*ort.Tensor[float32])
+	if !ok {
+		return nil, fmt.Errorf("不支持的输出类型")
+	}
+
+	embedding := append([]float32(nil), outputTensor.GetData()...)
+	var norm float64
+	for _, v := range embedding {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range embedding {
+			embedding[i] *= scale
+		}
+	}
+	return embedding, nil
+}
+
+// CosineSimilarity 计算两个向量的余弦相似度，长度不同或含零向量时返回 0
+func CosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / math.Sqrt(normA*normB))
+}
+
+// MatchIcons 将提示图标与候选裁剪图一一匹配，使总余弦相似度最大
+func (d *DdddOcr) MatchIcons(prompts, candidates [][]byte) ([]IconMatch, error) {
+	promptImgs := make([]image.Image, len(prompts))
+	for i, data := range prompts {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("解码提示图标 %d 失败: %w", i, err)
+		}
+		promptImgs[i] = img
+	}
+
+	candidateImgs := make([]image.Image, len(candidates))
+	for i, data := range candidates {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("解码候选图 %d 失败: %w", i, err)
+		}
+		candidateImgs[i] = img
+	}
+
+	return d.matchIconImages(promptImgs, candidateImgs)
+}
+
+// MatchIconsInImage 在背景图中查找提示条里的图标
+//
+// boxes 通常来自 Detection；为空时使用连通域回退查找候选区域。
+// 提示条按列投影切分为单个图标。
+func (d *DdddOcr) MatchIconsInImage(promptStrip, background []byte, boxes []BBox) ([]IconMatch, error) {
+	stripImg, _, err := image.Decode(bytes.NewReader(promptStrip))
+	if err != nil {
+		return nil, fmt.Errorf("解码提示条失败: %w", err)
+	}
+	bgImg, _, err := image.Decode(bytes.NewReader(background))
+	if err != nil {
+		return nil, fmt.Errorf("解码背景图失败: %w", err)
+	}
+
+	promptBoxes := splitPromptStrip(stripImg)
+	if len(promptBoxes) == 0 {
+		return nil, fmt.Errorf("提示条中未找到图标")
+	}
+
+	if len(boxes) == 0 {
+		boxes = findIconBoxes(bgImg)
+	}
+	if len(boxes) == 0 {
+		return nil, fmt.Errorf("背景图中未找到候选区域")
+	}
+
+	matches, err := d.matchIconImages(cropBoxes(stripImg, promptBoxes), cropBoxes(bgImg, boxes))
+	if err != nil {
+		return nil, err
+	}
+	for i := range matches {
+		if matches[i].CandidateIndex >= 0 {
+			matches[i].Box = boxes[matches[i].CandidateIndex]
+		}
+	}
+	return matches, nil
+}
+
+// FindIconBoxes 使用二值化与连通域查找图中的候选图标区域（无检测模型时的回退方案）
+func FindIconBoxes(imageData []byte) ([]BBox, error) {
+	img, _, err := image.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("解码失败: %w", err)
+	}
+	return findIconBoxes(img), nil
+}
+
+// matchIconImages 计算嵌入并求解最优分配
+func (d *DdddOcr) matchIconImages(prompts, candidates []image.Image) ([]IconMatch, error) {
+	promptEmb := make([][]float32, len(prompts))
+	for i, img := range prompts {
+		emb, err := d.embedImage(img)
+		if err != nil {
+			return nil, err
+		}
+		promptEmb[i] = emb
+	}
+
+	candidateEmb := make([][]float32, len(candidates))
+	for i, img := range candidates {
+		emb, err := d.embedImage(img)
+		if err != nil {
+			return nil, err
+		}
+		candidateEmb[i] = emb
+	}
+
+	similarity := make([][]float64, len(promptEmb))
+	for i := range promptEmb {
+		similarity[i] = make([]float64, len(candidateEmb))
+		for j := range candidateEmb {
+			similarity[i][j] = float64(CosineSimilarity(promptEmb[i], candidateEmb[j]))
+		}
+	}
+
+	assignment := maxWeightAssignment(similarity)
+	matches := make([]IconMatch, len(prompts))
+	for i, j := range assignment {
+		matches[i] = IconMatch{PromptIndex: i, CandidateIndex: j}
+		if j >= 0 {
+			matches[i].Score = float32(similarity[i][j])
+		}
+	}
+	return matches, nil
+}
+
+// maxWeightAssignment 匈牙利算法求最大权匹配，返回每行分配的列，未分配为 -1
+func maxWeightAssignment(weight [][]float64) []int {
+	rows := len(weight)
+	if rows == 0 {
+		return nil
+	}
+	cols := len(weight[0])
+
+	// 行数多于列数时转置求解
+	if rows > cols {
+		transposed := make([][]float64, cols)
+		for j := range transposed {
+			transposed[j] = make([]float64, rows)
+			for i := range weight {
+				transposed[j][i] = weight[i][j]
+			}
+		}
+		colAssign := maxWeightAssignment(transposed)
+		result := make([]int, rows)
+		for i := range result {
+			result[i] = -1
+		}
+		for j, i := range colAssign {
+			if i >= 0 {
+				result[i] = j
+			}
+		}
+		return result
+	}
+
+	// e-maxx 形式，下标从 1 开始，最小化 -weight
+	n, m := rows, cols
+	u := make([]float64, n+1)
+	v := make([]float64, m+1)
+	p := make([]int, m+1)
+	way := make([]int, m+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minV := make([]float64, m+1)
+		used := make([]bool, m+1)
+		for j := range minV {
+			minV[j] = math.Inf(1)
+		}
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := math.Inf(1)
+			j1 := 0
+			for j := 1; j <= m; j++ {
+				if used[j] {
+					continue
+				}
+				cur := -weight[i0-1][j-1] - u[i0] - v[j]
+				if cur < minV[j] {
+					minV[j] = cur
+					way[j] = j0
+				}
+				if minV[j] < delta {
+					delta = minV[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= m; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minV[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	result := make([]int, n)
+	for i := range result {
+		result[i] = -1
+	}
+	for j := 1; j <= m; j++ {
+		if p[j] > 0 {
+			result[p[j]-1] = j - 1
+		}
+	}
+	return result
+}
+
+// splitPromptStrip 按列投影将提示条切分为单个图标
+func splitPromptStrip(img image.Image) []BBox {
+	gray, w, h := grayPixels(img)
+	mask := foregroundMask(gray, w, h)
+
+	var boxes []BBox
+	start := -1
+	for x := 0; x <= w; x++ {
+		hasInk := false
+		if x < w {
+			for y := 0; y < h; y++ {
+				if mask[y*w+x] {
+					hasInk = true
+					break
+				}
+			}
+		}
+
+		if hasInk && start < 0 {
+			start = x
+		} else if !hasInk && start >= 0 {
+			// 过滤噪点列
+			if x-start >= 3 {
+				y1, y2 := h, 0
+				for yy := 0; yy < h; yy++ {
+					for xx := start; xx < x; xx++ {
+						if mask[yy*w+xx] {
+							y1 = min(y1, yy)
+							y2 = max(y2, yy+1)
+							break
+						}
+					}
+				}
+				boxes = append(boxes, BBox{X1: start, Y1: y1, X2: x, Y2: y2})
+			}
+			start = -1
+		}
+	}
+	return boxes
+}
+
+// findIconBoxes 连通域查找候选图标区域
+func findIconBoxes(img image.Image) []BBox {
+	gray, w, h := grayPixels(img)
+	mask := dilateMask(foregroundMask(gray, w, h), w, h, 2)
+
+	minArea := w * h / 400
+	maxArea := w * h / 4
+
+	var boxes []BBox
+	for _, comp := range connectedComponents(mask, w, h) {
+		boxArea := (comp.Box.X2 - comp.Box.X1) * (comp.Box.Y2 - comp.Box.Y1)
+		if comp.Area < minArea || boxArea > maxArea {
+			continue
+		}
+		boxes = append(boxes, comp.Box)
+	}
+	return boxes
+}
+
+// cropBoxes 按框裁剪图片，框坐标相对于图片左上角
+func cropBoxes(img image.Image, boxes []BBox) []image.Image {
+	crops := make([]image.Image, len(boxes))
+	for i, box := range boxes {
+		crops[i] = cropImage(img, image.Rect(box.X1, box.Y1, box.X2, box.Y2))
+	}
+	return crops
+}
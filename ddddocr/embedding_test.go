@@ -0,0 +1,164 @@
+package ddddocr
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float32
+	}{
+		{"相同方向", []float32{1, 2, 3}, []float32{2, 4, 6}, 1},
+		{"正交", []float32{1, 0}, []float32{0, 5}, 0},
+		{"相反方向", []float32{1, -1}, []float32{-2, 2}, -1},
+		{"长度不同", []float32{1, 2, 3}, []float32{1, 2}, 0},
+		{"前缀相同但长度不同", []float32{1, 0}, []float32{1, 0, 1}, 0},
+		{"零向量", []float32{0, 0}, []float32{1, 1}, 0},
+		{"空向量", nil, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CosineSimilarity(tt.a, tt.b); math.Abs(float64(got-tt.want)) > 1e-6 {
+				t.Fatalf("CosineSimilarity = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxWeightAssignment(t *testing.T) {
+	tests := []struct {
+		name   string
+		weight [][]float64
+		want   []int
+	}{
+		{"空矩阵", nil, nil},
+		{"单元素", [][]float64{{0.3}}, []int{0}},
+		{
+			// 贪心会把第 0 行分给第 0 列（0.9），最优解总和 0.8+0.8 更大
+			"贪心不是最优",
+			[][]float64{
+				{0.9, 0.8},
+				{0.8, 0.1},
+			},
+			[]int{1, 0},
+		},
+		{
+			"方阵",
+			[][]float64{
+				{0.1, 0.9, 0.2},
+				{0.8, 0.7, 0.1},
+				{0.3, 0.2, 0.6},
+			},
+			[]int{1, 0, 2},
+		},
+		{
+			"列多于行",
+			[][]float64{
+				{0.2, 0.9, 0.1, 0.3},
+				{0.1, 0.8, 0.7, 0.2},
+			},
+			[]int{1, 2},
+		},
+		{
+			"行多于列",
+			[][]float64{
+				{0.2, 0.1},
+				{0.9, 0.8},
+				{0.7, 0.1},
+			},
+			[]int{-1, 1, 0},
+		},
+		{"没有列", [][]float64{{}, {}}, []int{-1, -1}},
+		{
+			"负权重也必须分配",
+			[][]float64{
+				{-0.5, -0.1},
+				{-0.2, -0.9},
+			},
+			[]int{1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maxWeightAssignment(tt.weight); !slices.Equal(got, tt.want) {
+				t.Fatalf("maxWeightAssignment = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// 随机矩阵与穷举结果比较总权重
+func TestMaxWeightAssignmentBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		rows, cols := 1+rng.Intn(5), 1+rng.Intn(5)
+		weight := make([][]float64, rows)
+		for i := range weight {
+			weight[i] = make([]float64, cols)
+			for j := range weight[i] {
+				weight[i][j] = rng.Float64()*2 - 1
+			}
+		}
+
+		got := maxWeightAssignment(weight)
+		used := make([]bool, cols)
+		assigned := 0
+		var total float64
+		for i, j := range got {
+			if j < 0 {
+				continue
+			}
+			if used[j] {
+				t.Fatalf("%v: 列 %d 被重复分配", weight, j)
+			}
+			used[j] = true
+			assigned++
+			total += weight[i][j]
+		}
+		if assigned != min(rows, cols) {
+			t.Fatalf("%v: 分配 %d 行, want %d", weight, assigned, min(rows, cols))
+		}
+		if best := bruteForceAssignment(weight, 0, make([]bool, cols)); math.Abs(total-best) > 1e-9 {
+			t.Fatalf("%v: 总权重 %v, 最优 %v", weight, total, best)
+		}
+	}
+}
+
+// bruteForceAssignment 穷举每行的列（行多于列时允许不分配），分配数取 min(rows, cols)
+func bruteForceAssignment(weight [][]float64, row int, used []bool) float64 {
+	rows := len(weight)
+	if row == rows {
+		return 0
+	}
+
+	best := math.Inf(-1)
+	free := 0
+	for j := range used {
+		if !used[j] {
+			free++
+		}
+	}
+	// 剩余行数多于空闲列时当前行可以不分配
+	if rows-row > free {
+		best = bruteForceAssignment(weight, row+1, used)
+	}
+	if free == 0 {
+		return best
+	}
+	for j := range used {
+		if used[j] {
+			continue
+		}
+		used[j] = true
+		best = max(best, weight[row][j]+bruteForceAssignment(weight, row+1, used))
+		used[j] = false
+	}
+	return best
+}
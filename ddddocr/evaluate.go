@@ -0,0 +1,311 @@
+package ddddocr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// ============================================================================
+// 检测评估
+// ============================================================================
+
+// EvalOptions 评估选项
+type EvalOptions struct {
+	IoUThreshold   float64 // 计算 Precision/Recall 的 IoU 阈值，默认 0.5
+	ScoreThreshold float32 // 计算 Precision/Recall 时保留的最低分数，AP 始终使用全部预测
+	ClassAgnostic  bool    // 忽略类别（单类检测器或类别名与标注不一致时使用）
+	WorstN         int     // 报告中列出的最差图片数量，默认 10
+}
+
+// ClassAP 单个类别的 AP
+type ClassAP struct {
+	Class  string  `json:"class"`
+	NumGT  int     `json:"num_gt"`
+	AP50   float64 `json:"ap50"`
+	AP5095 float64 `json:"ap50_95"`
+}
+
+// ImageEval 单张图片的匹配统计
+type ImageEval struct {
+	Path string  `json:"path"`
+	TP   int     `json:"tp"`
+	FP   int     `json:"fp"`
+	FN   int     `json:"fn"`
+	F1   float64 `json:"f1"`
+}
+
+// EvalReport 评估报告
+type EvalReport struct {
+	IoUThreshold float64     `json:"iou_threshold"`
+	TP           int         `json:"tp"`
+	FP           int         `json:"fp"`
+	FN           int         `json:"fn"`
+	Precision    float64     `json:"precision"`
+	Recall       float64     `json:"recall"`
+	F1           float64     `json:"f1"`
+	MAP50        float64     `json:"map50"`
+	MAP5095      float64     `json:"map50_95"`
+	PerClass     []ClassAP   `json:"per_class"`
+	WorstImages  []ImageEval `json:"worst_images"`
+}
+
+// EvaluateDetection 对标注数据逐张执行检测并评估，图片从 AnnotatedImage.Path 读取
+func (d *DdddOcr) EvaluateDetection(groundTruth []AnnotatedImage, detOpts DetectionOptions, opts EvalOptions) (*EvalReport, error) {
+	predictions := make([]AnnotatedImage, len(groundTruth))
+	for i, gt := range groundTruth {
+		data, err := os.ReadFile(gt.Path)
+		if err != nil {
+			return nil, fmt.Errorf("读取图片失败: %w", err)
+		}
+		dets, err := d.DetectionWithOptions(data, detOpts)
+		if err != nil {
+			return nil, fmt.Errorf("检测 %s 失败: %w", gt.Path, err)
+		}
+		predictions[i] = AnnotatedImage{Path: gt.Path, Width: gt.Width, Height: gt.Height, Detections: dets}
+	}
+	return EvaluateDetections(groundTruth, predictions, opts)
+}
+
+// EvaluateDetections 比较预测与标注，计算 Precision/Recall、mAP@0.5、mAP@0.5:0.95 与各类别 AP
+//
+// 预测按图片路径与标注对应，缺少预测的图片视为没有检出任何目标。
+// 没有 ClassName 的预测框按标注中相同 ClassID 的类别名参与匹配。
+func EvaluateDetections(groundTruth, predictions []AnnotatedImage, opts EvalOptions) (*EvalReport, error) {
+	if opts.IoUThreshold <= 0 {
+		opts.IoUThreshold = 0.5
+	}
+	if opts.WorstN <= 0 {
+		opts.WorstN = 10
+	}
+
+	predByPath := make(map[string][]Detection, len(predictions))
+	for _, p := range predictions {
+		predByPath[filepath.Clean(p.Path)] = p.Detections
+	}
+
+	gts := make([][]Detection, len(groundTruth))
+	preds := make([][]Detection, len(groundTruth))
+	matched := make(map[string]bool, len(predictions))
+	for i, gt := range groundTruth {
+		key := filepath.Clean(gt.Path)
+		gts[i] = gt.Detections
+		preds[i] = predByPath[key]
+		matched[key] = true
+	}
+	for _, p := range predictions {
+		if !matched[filepath.Clean(p.Path)] {
+			return nil, fmt.Errorf("预测图片 %s 没有对应的标注", p.Path)
+		}
+	}
+
+	// 没有类别名的框（如内置检测器的输出）按标注中的 ClassID → 类别名映射，
+	// 避免 "0" 与 "text" 这类名称不一致导致类别永远无法匹配
+	idNames := make(map[int]string)
+	for _, dets := range gts {
+		for _, det := range dets {
+			if det.ClassName != "" {
+				idNames[det.ClassID] = det.ClassName
+			}
+		}
+	}
+
+	classOf := func(det Detection) string {
+		if opts.ClassAgnostic {
+			return ""
+		}
+		if det.ClassName != "" {
+			return det.ClassName
+		}
+		if name, ok := idNames[det.ClassID]; ok {
+			return name
+		}
+		return strconv.Itoa(det.ClassID)
+	}
+
+	report := &EvalReport{IoUThreshold: opts.IoUThreshold}
+
+	// Precision / Recall / 每图统计
+	var images []ImageEval
+	for i := range groundTruth {
+		var kept []Detection
+		for _, det := range preds[i] {
+			if det.Score >= opts.ScoreThreshold {
+				kept = append(kept, det)
+			}
+		}
+		tp, fp, fn := countMatches(gts[i], kept, opts.IoUThreshold, classOf)
+		report.TP += tp
+		report.FP += fp
+		report.FN += fn
+		images = append(images, ImageEval{
+			Path: groundTruth[i].Path,
+			TP:   tp,
+			FP:   fp,
+			FN:   fn,
+			F1:   f1Score(tp, fp, fn),
+		})
+	}
+	report.Precision = safeDiv(report.TP, report.TP+report.FP)
+	report.Recall = safeDiv(report.TP, report.TP+report.FN)
+	report.F1 = f1Score(report.TP, report.FP, report.FN)
+
+	sort.SliceStable(images, func(i, j int) bool {
+		ei := images[i].FP + images[i].FN
+		ej := images[j].FP + images[j].FN
+		if ei != ej {
+			return ei > ej
+		}
+		return images[i].F1 < images[j].F1
+	})
+	for _, img := range images {
+		if len(report.WorstImages) >= opts.WorstN || img.FP+img.FN == 0 {
+			break
+		}
+		report.WorstImages = append(report.WorstImages, img)
+	}
+
+	// 各类别 AP
+	numGT := make(map[string]int)
+	for _, dets := range gts {
+		for _, det := range dets {
+			numGT[classOf(det)]++
+		}
+	}
+	classes := make([]string, 0, len(numGT))
+	for class := range numGT {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	for _, class := range classes {
+		ap := ClassAP{Class: class, NumGT: numGT[class]}
+		for t := 0; t < 10; t++ {
+			iouThr := 0.5 + 0.05*float64(t)
+			v := averagePrecision(gts, preds, class, iouThr, classOf)
+			if t == 0 {
+				ap.AP50 = v
+			}
+			ap.AP5095 += v / 10
+		}
+		report.PerClass = append(report.PerClass, ap)
+		report.MAP50 += ap.AP50
+		report.MAP5095 += ap.AP5095
+	}
+	if len(classes) > 0 {
+		report.MAP50 /= float64(len(classes))
+		report.MAP5095 /= float64(len(classes))
+	}
+
+	return report, nil
+}
+
+// countMatches 按分数贪心匹配同类框，返回 TP、FP、FN
+func countMatches(gt, pred []Detection, iouThr float64, classOf func(Detection) string) (int, int, int) {
+	pred = append([]Detection(nil), pred...)
+	sort.SliceStable(pred, func(i, j int) bool {
+		return pred[i].Score > pred[j].Score
+	})
+
+	used := make([]bool, len(gt))
+	tp := 0
+	for _, p := range pred {
+		if j := bestMatch(gt, used, p, iouThr, classOf); j >= 0 {
+			used[j] = true
+			tp++
+		}
+	}
+	return tp, len(pred) - tp, len(gt) - tp
+}
+
+// bestMatch 查找与预测框 IoU 最大且未被占用的同类标注框
+func bestMatch(gt []Detection, used []bool, p Detection, iouThr float64, classOf func(Detection) string) int {
+	best := -1
+	bestIoU := iouThr
+	class := classOf(p)
+	for j, g := range gt {
+		if used[j] || classOf(g) != class {
+			continue
+		}
+		if iou := float64(computeIoU(g.Box, p.Box)); iou >= bestIoU {
+			bestIoU = iou
+			best = j
+		}
+	}
+	return best
+}
+
+// averagePrecision COCO 风格 101 点插值 AP
+func averagePrecision(gts, preds [][]Detection, class string, iouThr float64, classOf func(Detection) string) float64 {
+	type scored struct {
+		image int
+		det   Detection
+	}
+
+	var all []scored
+	total := 0
+	used := make([][]bool, len(gts))
+	for i := range gts {
+		used[i] = make([]bool, len(gts[i]))
+		for _, g := range gts[i] {
+			if classOf(g) == class {
+				total++
+			}
+		}
+		for _, p := range preds[i] {
+			if classOf(p) == class {
+				all = append(all, scored{image: i, det: p})
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].det.Score > all[j].det.Score
+	})
+
+	precision := make([]float64, len(all))
+	recall := make([]float64, len(all))
+	tp := 0
+	for k, s := range all {
+		if j := bestMatch(gts[s.image], used[s.image], s.det, iouThr, classOf); j >= 0 {
+			used[s.image][j] = true
+			tp++
+		}
+		precision[k] = float64(tp) / float64(k+1)
+		recall[k] = float64(tp) / float64(total)
+	}
+
+	// 精度包络：从后往前取最大值
+	for k := len(precision) - 2; k >= 0; k-- {
+		precision[k] = max(precision[k], precision[k+1])
+	}
+
+	var sum float64
+	k := 0
+	for i := 0; i <= 100; i++ {
+		r := float64(i) / 100
+		for k < len(recall) && recall[k] < r {
+			k++
+		}
+		if k < len(precision) {
+			sum += precision[k]
+		}
+	}
+	return sum / 101
+}
+
+func safeDiv(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+func f1Score(tp, fp, fn int) float64 {
+	return safeDiv(2*tp, 2*tp+fp+fn)
+}
@@ -0,0 +1,200 @@
+package ddddocr
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func testBox(x1, y1, x2, y2 int) BBox {
+	return BBox{X1: x1, Y1: y1, X2: x2, Y2: y2}
+}
+
+func testDet(b BBox, score float32, class string) Detection {
+	return Detection{Box: b, Score: score, ClassName: class}
+}
+
+func classNameOf(d Detection) string {
+	if d.ClassName != "" {
+		return d.ClassName
+	}
+	return strconv.Itoa(d.ClassID)
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCountMatches(t *testing.T) {
+	a := testBox(0, 0, 10, 10)
+	b := testBox(20, 0, 30, 10)
+	shifted := testBox(4, 0, 14, 10) // 与 a 的 IoU = 60/140 ≈ 0.43
+
+	tests := []struct {
+		name       string
+		gt, pred   []Detection
+		tp, fp, fn int
+	}{
+		{"全部命中", []Detection{testDet(a, 1, "x"), testDet(b, 1, "x")}, []Detection{testDet(a, 0.9, "x"), testDet(b, 0.8, "x")}, 2, 0, 0},
+		{"漏检一个", []Detection{testDet(a, 1, "x"), testDet(b, 1, "x")}, []Detection{testDet(a, 0.9, "x")}, 1, 0, 1},
+		{"重复检测", []Detection{testDet(a, 1, "x")}, []Detection{testDet(a, 0.9, "x"), testDet(a, 0.8, "x")}, 1, 1, 0},
+		{"IoU 不足", []Detection{testDet(a, 1, "x")}, []Detection{testDet(shifted, 0.9, "x")}, 0, 1, 1},
+		{"类别不一致", []Detection{testDet(a, 1, "x")}, []Detection{testDet(a, 0.9, "y")}, 0, 1, 1},
+		{"没有标注", nil, []Detection{testDet(a, 0.9, "x"), testDet(b, 0.8, "x")}, 0, 2, 0},
+		{"没有预测", []Detection{testDet(a, 1, "x")}, nil, 0, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp, fp, fn := countMatches(tt.gt, tt.pred, 0.5, classNameOf)
+			if tp != tt.tp || fp != tt.fp || fn != tt.fn {
+				t.Fatalf("TP/FP/FN = %d/%d/%d, want %d/%d/%d", tp, fp, fn, tt.tp, tt.fp, tt.fn)
+			}
+		})
+	}
+}
+
+func TestAveragePrecision(t *testing.T) {
+	a := testBox(0, 0, 10, 10)
+	b := testBox(20, 0, 30, 10)
+
+	tests := []struct {
+		name  string
+		gts   [][]Detection
+		preds [][]Detection
+		want  float64
+	}{
+		{
+			name:  "完美检测",
+			gts:   [][]Detection{{testDet(a, 1, "x"), testDet(b, 1, "x")}},
+			preds: [][]Detection{{testDet(a, 0.9, "x"), testDet(b, 0.8, "x")}},
+			want:  1,
+		},
+		{
+			// 召回率 0~0.5 的 51 个插值点精度为 1，其余为 0
+			name:  "一半召回",
+			gts:   [][]Detection{{testDet(a, 1, "x"), testDet(b, 1, "x")}},
+			preds: [][]Detection{{testDet(a, 0.9, "x")}},
+			want:  51.0 / 101,
+		},
+		{
+			// PR 序列 (1, 0.5) (0.5, 0.5) (2/3, 1)，包络后 51 个点精度 1，50 个点精度 2/3
+			name:  "重复检测",
+			gts:   [][]Detection{{testDet(a, 1, "x"), testDet(b, 1, "x")}},
+			preds: [][]Detection{{testDet(a, 0.9, "x"), testDet(a, 0.8, "x"), testDet(b, 0.7, "x")}},
+			want:  (51 + 50*2.0/3) / 101,
+		},
+		{
+			// 得分最高的是误检：PR 序列 (0, 0) (0.5, 0.5) (2/3, 1)
+			name:  "高分误检",
+			gts:   [][]Detection{{testDet(a, 1, "x"), testDet(b, 1, "x")}},
+			preds: [][]Detection{{testDet(testBox(50, 50, 60, 60), 0.95, "x"), testDet(a, 0.9, "x"), testDet(b, 0.7, "x")}},
+			want:  2.0 / 3,
+		},
+		{
+			name:  "类别不一致",
+			gts:   [][]Detection{{testDet(a, 1, "x")}},
+			preds: [][]Detection{{testDet(a, 0.9, "y")}},
+			want:  0,
+		},
+		{
+			name:  "跨图片",
+			gts:   [][]Detection{{testDet(a, 1, "x")}, {testDet(b, 1, "x")}},
+			preds: [][]Detection{{testDet(a, 0.9, "x")}, {testDet(b, 0.8, "x")}},
+			want:  1,
+		},
+		{
+			name:  "没有该类标注",
+			gts:   [][]Detection{{testDet(a, 1, "y")}},
+			preds: [][]Detection{{testDet(a, 0.9, "x")}},
+			want:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := averagePrecision(tt.gts, tt.preds, "x", 0.5, classNameOf)
+			if !approxEqual(got, tt.want) {
+				t.Fatalf("AP = %.6f, want %.6f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateDetections(t *testing.T) {
+	a := testBox(0, 0, 10, 10)
+	b := testBox(20, 0, 30, 10)
+
+	gt := []AnnotatedImage{
+		{Path: "1.png", Detections: []Detection{testDet(a, 1, "x"), testDet(b, 1, "y")}},
+		{Path: "2.png"},
+	}
+	pred := []AnnotatedImage{
+		{Path: "1.png", Detections: []Detection{testDet(a, 0.9, "x"), testDet(b, 0.8, "x")}},
+		{Path: "2.png", Detections: []Detection{testDet(a, 0.7, "x")}},
+	}
+
+	report, err := EvaluateDetections(gt, pred, EvalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TP != 1 || report.FP != 2 || report.FN != 1 {
+		t.Fatalf("TP/FP/FN = %d/%d/%d, want 1/2/1", report.TP, report.FP, report.FN)
+	}
+	// x: 得分序列 TP(0.9) FP(0.8) FP(0.7)，AP = 1；y 没有预测，AP = 0
+	if !approxEqual(report.MAP50, 0.5) || len(report.PerClass) != 2 {
+		t.Fatalf("mAP50 = %.4f, classes = %d, want 0.5, 2", report.MAP50, len(report.PerClass))
+	}
+	if len(report.WorstImages) != 2 || report.WorstImages[0].Path != "1.png" {
+		t.Fatalf("最差图片排序错误: %+v", report.WorstImages)
+	}
+
+	report, err = EvaluateDetections(gt, pred, EvalOptions{ClassAgnostic: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TP != 2 || report.FP != 1 || report.FN != 0 || !approxEqual(report.MAP50, 1) {
+		t.Fatalf("类别无关: TP/FP/FN = %d/%d/%d mAP50 = %.4f", report.TP, report.FP, report.FN, report.MAP50)
+	}
+}
+
+func TestEvaluateDetectionsMapsClassIDs(t *testing.T) {
+	a := testBox(0, 0, 10, 10)
+	gt := []AnnotatedImage{{Path: "1.png", Detections: []Detection{{Box: a, Score: 1, ClassID: 0, ClassName: "text"}}}}
+	pred := []AnnotatedImage{{Path: "1.png", Detections: []Detection{{Box: a, Score: 0.9, ClassID: 0}}}}
+
+	report, err := EvaluateDetections(gt, pred, EvalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TP != 1 || !approxEqual(report.MAP50, 1) {
+		t.Fatalf("无类别名的预测应按 ClassID 映射: TP = %d, mAP50 = %.4f", report.TP, report.MAP50)
+	}
+}
+
+func TestEvaluateDetectionsWithoutGroundTruth(t *testing.T) {
+	a := testBox(0, 0, 10, 10)
+
+	// 预测的图片没有对应标注
+	_, err := EvaluateDetections(
+		[]AnnotatedImage{{Path: "1.png"}},
+		[]AnnotatedImage{{Path: "2.png", Detections: []Detection{testDet(a, 0.9, "x")}}},
+		EvalOptions{},
+	)
+	if err == nil {
+		t.Fatal("预测图片没有对应标注时应返回错误")
+	}
+
+	// 标注中没有任何目标：全部为误检，没有可计算 AP 的类别
+	report, err := EvaluateDetections(
+		[]AnnotatedImage{{Path: "1.png"}},
+		[]AnnotatedImage{{Path: "1.png", Detections: []Detection{testDet(a, 0.9, "x")}}},
+		EvalOptions{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TP != 0 || report.FP != 1 || report.FN != 0 || report.MAP50 != 0 || len(report.PerClass) != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+}
@@ -0,0 +1,150 @@
+package ddddocr
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// ============================================================================
+// FFT 与快速模板匹配
+// ============================================================================
+
+// nextPow2 不小于 n 的最小 2 的幂
+func nextPow2(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
+
+// fft1D 原地基 2 FFT，len(a) 必须为 2 的幂
+func fft1D(a []complex128, inverse bool) {
+	n := len(a)
+	if n <= 1 {
+		return
+	}
+
+	// 位反转置换
+	shift := bits.UintSize - bits.Len(uint(n-1))
+	for i := 0; i < n; i++ {
+		j := int(bits.Reverse(uint(i)) >> shift)
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	for size := 2; size <= n; size <<= 1 {
+		half := size >> 1
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < half; k++ {
+				u := a[start+k]
+				v := a[start+k+half] * w
+				a[start+k] = u + v
+				a[start+k+half] = u - v
+				w *= step
+			}
+		}
+	}
+
+	if inverse {
+		scale := complex(1/float64(n), 0)
+		for i := range a {
+			a[i] *= scale
+		}
+	}
+}
+
+// fft2D 对 w×h 行优先数据做二维 FFT，w、h 必须为 2 的幂
+func fft2D(data []complex128, w, h int, inverse bool) {
+	for y := 0; y < h; y++ {
+		fft1D(data[y*w:(y+1)*w], inverse)
+	}
+
+	col := make([]complex128, h)
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			col[y] = data[y*w+x]
+		}
+		fft1D(col, inverse)
+		for y := 0; y < h; y++ {
+			data[y*w+x] = col[y]
+		}
+	}
+}
+
+// crossCorrelate 计算 valid 区域的互相关 c(x, y) = Σ tpl(u, v)·bg(x+u, y+v)
+//
+// 返回 (bgW-tplW+1)×(bgH-tplH+1) 的结果。
+func crossCorrelate(bg []float64, bgW, bgH int, tpl []float64, tplW, tplH int) []float64 {
+	fw, fh := nextPow2(bgW), nextPow2(bgH)
+
+	// 两路实信号打包进一次复数 FFT: z = bg + i·tpl
+	z := make([]complex128, fw*fh)
+	for y := 0; y < bgH; y++ {
+		for x := 0; x < bgW; x++ {
+			z[y*fw+x] = complex(bg[y*bgW+x], 0)
+		}
+	}
+	for y := 0; y < tplH; y++ {
+		for x := 0; x < tplW; x++ {
+			z[y*fw+x] += complex(0, tpl[y*tplW+x])
+		}
+	}
+	fft2D(z, fw, fh, false)
+
+	// 拆分频谱: B = (Z[k] + conj(Z[-k]))/2, T = (Z[k] - conj(Z[-k]))/(2i)
+	// 互相关频谱为 B·conj(T)
+	prod := make([]complex128, fw*fh)
+	for ky := 0; ky < fh; ky++ {
+		ny := (fh - ky) % fh
+		for kx := 0; kx < fw; kx++ {
+			nx := (fw - kx) % fw
+			zk := z[ky*fw+kx]
+			zn := cmplx.Conj(z[ny*fw+nx])
+			b := (zk + zn) / 2
+			t := (zk - zn) / complex(0, 2)
+			prod[ky*fw+kx] = b * cmplx.Conj(t)
+		}
+	}
+	fft2D(prod, fw, fh, true)
+
+	rw, rh := bgW-tplW+1, bgH-tplH+1
+	result := make([]float64, rw*rh)
+	for y := 0; y < rh; y++ {
+		for x := 0; x < rw; x++ {
+			result[y*rw+x] = real(prod[y*fw+x])
+		}
+	}
+	return result
+}
+
+// integralImages 计算像素值与平方值的积分图，尺寸 (w+1)×(h+1)
+func integralImages(data []float64, w, h int) ([]float64, []float64) {
+	sum := make([]float64, (w+1)*(h+1))
+	sqSum := make([]float64, (w+1)*(h+1))
+	stride := w + 1
+
+	for y := 0; y < h; y++ {
+		var rowSum, rowSq float64
+		for x := 0; x < w; x++ {
+			v := data[y*w+x]
+			rowSum += v
+			rowSq += v * v
+			sum[(y+1)*stride+x+1] = sum[y*stride+x+1] + rowSum
+			sqSum[(y+1)*stride+x+1] = sqSum[y*stride+x+1] + rowSq
+		}
+	}
+	return sum, sqSum
+}
+
+// rectSum 用积分图求 [x, x+w) × [y, y+h) 的和
+func rectSum(integral []float64, stride, x, y, w, h int) float64 {
+	return integral[(y+h)*stride+x+w] - integral[y*stride+x+w] - integral[(y+h)*stride+x] + integral[y*stride+x]
+}
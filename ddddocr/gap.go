@@ -0,0 +1,196 @@
+package ddddocr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+)
+
+// ============================================================================
+// 单图缺口检测
+// ============================================================================
+
+// SlideGapOptions 单图缺口检测选项
+type SlideGapOptions struct {
+	MinSize int         // 缺口最小边长（像素），0 表示背景图短边的 1/8
+	MaxSize int         // 缺口最大边长（像素），0 表示背景图短边的 3/5
+	Drag    DragOptions // 计算 DragDistance 使用的渲染尺寸与滑块初始偏移
+}
+
+// SlideGapResult 单图缺口检测结果
+type SlideGapResult struct {
+	Target       []int   `json:"target"`        // 缺口外接框 [x1, y1, x2, y2]
+	Area         int     `json:"area"`          // 缺口像素数
+	Confidence   float64 `json:"confidence"`    // 置信度 [0, 1]
+	DragDistance float64 `json:"drag_distance"` // 滑块拖动距离（CSS 像素），见 SlideGapOptions.Drag
+}
+
+// gapCandidate 缺口候选及其评分
+type gapCandidate struct {
+	comp       component
+	confidence float64
+}
+
+// SlideGapDetect 只根据背景图检测缺口位置
+//
+// 适用于只提供背景图、缺口以变暗或描边形式呈现的验证码。
+func (d *DdddOcr) SlideGapDetect(backgroundBytes []byte) (*SlideGapResult, error) {
+	return d.SlideGapDetectWithOptions(backgroundBytes, SlideGapOptions{})
+}
+
+// SlideGapDetectWithOptions 使用自定义选项检测缺口
+//
+// 缺口区域相对周围的明暗差异、轮廓是否被边缘闭合以及形状（接近方形、填充饱满）
+// 共同决定置信度，返回置信度最高的候选。
+func (d *DdddOcr) SlideGapDetectWithOptions(backgroundBytes []byte, opts SlideGapOptions) (*SlideGapResult, error) {
+	img, _, err := image.Decode(bytes.NewReader(backgroundBytes))
+	if err != nil {
+		return nil, fmt.Errorf("解码背景图失败: %w", err)
+	}
+
+	best, ok := detectGap(img, opts)
+	if !ok {
+		return nil, fmt.Errorf("未检测到缺口")
+	}
+
+	box := best.comp.Box
+	return &SlideGapResult{
+		Target:       []int{box.X1, box.Y1, box.X2, box.Y2},
+		Area:         best.comp.Area,
+		Confidence:   best.confidence,
+		DragDistance: DragDistance(box.X1, 0, img.Bounds().Dx(), opts.Drag),
+	}, nil
+}
+
+// detectGap 在背景图中寻找最像缺口的区域
+func detectGap(img image.Image, opts SlideGapOptions) (gapCandidate, bool) {
+	gray, w, h := grayPixels(img)
+	if w < 3 || h < 3 {
+		return gapCandidate{}, false
+	}
+
+	short := min(w, h)
+	minSize, maxSize := opts.MinSize, opts.MaxSize
+	if minSize <= 0 {
+		minSize = max(short/8, 8)
+	}
+	if maxSize <= 0 {
+		maxSize = short * 3 / 5
+	}
+
+	// 以大窗口均值作为"周围亮度"，缺口是相对它整体偏暗（或偏亮）的区域
+	localMean := boxMean(gray, w, h, max(short/4, 8))
+
+	// 边缘图膨胀 1 像素，用于判断候选轮廓是否被边缘闭合
+	edge := canny(img, DefaultCannyOptions())
+	edgeMask := make([]bool, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			edgeMask[y*w+x] = edge.Pix[y*edge.Stride+x] > 0
+		}
+	}
+	edgeMask = dilateMask(edgeMask, w, h, 1)
+
+	var best gapCandidate
+	found := false
+	consider := func(mask []bool, diff []uint8) {
+		for _, c := range scoreGapCandidates(mask, diff, edgeMask, w, h, minSize, maxSize) {
+			if !found || c.confidence > best.confidence {
+				best = c
+				found = true
+			}
+		}
+	}
+
+	// 1. 明暗线索：整体偏暗（阴影）或偏亮（半透明白色）的区域
+	absDiff := make([]uint8, w*h)
+	for _, sign := range []float64{1, -1} {
+		diff := make([]uint8, w*h)
+		for i, v := range gray {
+			diff[i] = uint8(math.Max(0, math.Min(255, sign*(localMean[i]-float64(v)))))
+			absDiff[i] = max(absDiff[i], diff[i])
+		}
+
+		threshold := max(otsuThreshold(diff), 20)
+		mask := make([]bool, w*h)
+		for i, v := range diff {
+			mask[i] = v > threshold
+		}
+		// 填洞使描边型缺口成为实心区域，再开运算去噪
+		consider(openMask(fillHoles(mask, w, h), w, h, 1), diff)
+	}
+
+	// 2. 轮廓线索：边缘围成的闭合区域，开运算去掉未闭合的边缘线，
+	// 再腐蚀 1 像素抵消边缘图的膨胀
+	consider(erodeMask(openMask(fillHoles(edgeMask, w, h), w, h, 2), w, h, 1), absDiff)
+
+	return best, found
+}
+
+// scoreGapCandidates 按尺寸与形状过滤连通域并计算置信度
+func scoreGapCandidates(mask []bool, diff []uint8, edgeMask []bool, w, h, minSize, maxSize int) []gapCandidate {
+	labels, comps := labelComponents(mask, w, h)
+
+	diffSum := make([]float64, len(comps))
+	boundary := make([]int, len(comps))
+	closed := make([]int, len(comps))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			l := labels[i]
+			if l < 0 {
+				continue
+			}
+			diffSum[l] += float64(diff[i])
+
+			// 4 邻域存在背景像素即为轮廓像素
+			if x == 0 || y == 0 || x == w-1 || y == h-1 ||
+				labels[i-1] != l || labels[i+1] != l || labels[i-w] != l || labels[i+w] != l {
+				boundary[l]++
+				if edgeMask[i] {
+					closed[l]++
+				}
+			}
+		}
+	}
+
+	var candidates []gapCandidate
+	for l, c := range comps {
+		bw, bh := c.Box.X2-c.Box.X1, c.Box.Y2-c.Box.Y1
+		if bw < minSize || bh < minSize || bw > maxSize || bh > maxSize {
+			continue
+		}
+		squareness := float64(min(bw, bh)) / float64(max(bw, bh))
+		fill := float64(c.Area) / float64(bw*bh)
+		if squareness < 0.5 || fill < 0.45 {
+			continue
+		}
+
+		contrast := math.Min(1, diffSum[l]/float64(c.Area)/80)
+		closure := float64(closed[l]) / float64(max(boundary[l], 1))
+		confidence := 0.35*contrast + 0.35*closure + 0.15*squareness + 0.15*math.Min(1, fill/0.75)
+
+		candidates = append(candidates, gapCandidate{comp: c, confidence: confidence})
+	}
+	return candidates
+}
+
+// boxMean 方形窗口均值（窗口在图像边界处截断）
+func boxMean(gray []uint8, w, h, radius int) []float64 {
+	data := make([]float64, len(gray))
+	for i, v := range gray {
+		data[i] = float64(v)
+	}
+	sum, _ := integralImages(data, w, h)
+
+	mean := make([]float64, len(gray))
+	for y := 0; y < h; y++ {
+		y1, y2 := max(0, y-radius), min(h, y+radius+1)
+		for x := 0; x < w; x++ {
+			x1, x2 := max(0, x-radius), min(w, x+radius+1)
+			mean[y*w+x] = rectSum(sum, w+1, x1, y1, x2-x1, y2-y1) / float64((x2-x1)*(y2-y1))
+		}
+	}
+	return mean
+}
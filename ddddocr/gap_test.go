@@ -0,0 +1,89 @@
+package ddddocr
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// noisyBackground 平滑渐变叠加逐像素噪声的背景图
+func noisyBackground(w, h int, seed int64) *image.Gray {
+	rng := rand.New(rand.NewSource(seed))
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := 140 + 40*math.Sin(float64(x)/23) + 25*math.Cos(float64(y)/17) + rng.NormFloat64()*12
+			img.Pix[y*img.Stride+x] = uint8(max(0, min(255, v)))
+		}
+	}
+	return img
+}
+
+// carveGap 在 rect 处刻出带斜面的阴影缺口：内部变暗，左上边缘提亮、右下边缘压暗
+func carveGap(img *image.Gray, rect image.Rectangle, bevel int) {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			i := y*img.Stride + x
+			v := float64(img.Pix[i]) * 0.5
+			switch {
+			case x-rect.Min.X < bevel || y-rect.Min.Y < bevel:
+				v = float64(img.Pix[i]) + 50
+			case rect.Max.X-1-x < bevel || rect.Max.Y-1-y < bevel:
+				v -= 30
+			}
+			img.Pix[i] = uint8(max(0, min(255, v)))
+		}
+	}
+}
+
+func encodeTestPNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSlideGapDetect(t *testing.T) {
+	d := &DdddOcr{}
+	for _, gap := range []image.Rectangle{
+		image.Rect(180, 50, 224, 94),
+		image.Rect(90, 20, 136, 66),
+		image.Rect(230, 95, 272, 137),
+	} {
+		img := noisyBackground(300, 150, int64(gap.Min.X))
+		carveGap(img, gap, 3)
+
+		result, err := d.SlideGapDetect(encodeTestPNG(t, img))
+		if err != nil {
+			t.Fatalf("缺口 %v: %v", gap, err)
+		}
+		// 斜面高光不比周围暗，外接框可能只覆盖变暗的内部
+		want := []int{gap.Min.X, gap.Min.Y, gap.Max.X, gap.Max.Y}
+		for i := range want {
+			if d := result.Target[i] - want[i]; d < -4 || d > 4 {
+				t.Fatalf("缺口 %v: Target = %v", gap, result.Target)
+			}
+		}
+		if result.Confidence < 0.6 {
+			t.Fatalf("缺口 %v: 置信度 %.3f 过低", gap, result.Confidence)
+		}
+	}
+}
+
+func TestSlideGapDetectWithoutGap(t *testing.T) {
+	d := &DdddOcr{}
+	for seed := int64(1); seed <= 3; seed++ {
+		result, err := d.SlideGapDetect(encodeTestPNG(t, noisyBackground(300, 150, seed)))
+		if err != nil {
+			continue
+		}
+		if result.Confidence >= 0.5 {
+			t.Fatalf("无缺口图片置信度 %.3f，Target = %v", result.Confidence, result.Target)
+		}
+	}
+}
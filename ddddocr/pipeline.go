@@ -0,0 +1,198 @@
+package ddddocr
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// ============================================================================
+// 检测 + 识别
+// ============================================================================
+
+// TextDetection 检测框及其识别结果
+type TextDetection struct {
+	Detection
+	Text       string  `json:"text"`
+	Confidence float32 `json:"confidence"` // 识别字符的平均概率
+}
+
+// DetectAndRecognizeOptions 检测 + 识别选项
+type DetectAndRecognizeOptions struct {
+	Detection   DetectionOptions // 检测选项
+	Margin      int              // 裁剪前向外扩展的像素（限制在图片范围内）
+	MarginRatio float64          // 按框高比例向外扩展，与 Margin 取较大者
+	Padding     int              // 裁剪后四周补白的像素
+}
+
+// DetectAndRecognize 检测图片中的目标并识别每个区域的文字，结果按阅读顺序排列
+//
+// 需要同时启用目标检测与 OCR（Det=true，Ocr=true 或 ImportOnnxPath）。
+func (d *DdddOcr) DetectAndRecognize(imageData []byte) ([]TextDetection, error) {
+	return d.DetectAndRecognizeWithOptions(imageData, DetectAndRecognizeOptions{Margin: 2})
+}
+
+// DetectAndRecognizeWithOptions 带选项的检测 + 识别
+func (d *DdddOcr) DetectAndRecognizeWithOptions(imageData []byte, opts DetectAndRecognizeOptions) ([]TextDetection, error) {
+	if err := d.ensureOcr(); err != nil {
+		return nil, err
+	}
+	if err := d.ensureDet(); err != nil {
+		return nil, err
+	}
+
+	img, err := decodeImageCV(imageData)
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %w", err)
+	}
+	bounds := img.Bounds()
+
+	detections, err := d.detectImage(img, opts.Detection)
+	if err != nil {
+		return nil, err
+	}
+
+	detections = readingOrder(detections)
+	results := make([]TextDetection, 0, len(detections))
+	for _, det := range detections {
+		box := det.Box
+		margin := max(opts.Margin, int(float64(box.Y2-box.Y1)*opts.MarginRatio+0.5))
+		rect := image.Rect(box.X1-margin, box.Y1-margin, box.X2+margin, box.Y2+margin).
+			Intersect(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		if rect.Empty() {
+			continue
+		}
+
+		crop := padImage(cropImage(img, rect), opts.Padding)
+		text, confidence, err := d.recognizeImage(crop)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, TextDetection{
+			Detection:  det,
+			Text:       text,
+			Confidence: confidence,
+		})
+	}
+	return results, nil
+}
+
+// recognizeImage 识别已解码的图片，返回文本及识别字符的平均概率
+func (d *DdddOcr) recognizeImage(img image.Image) (string, float32, error) {
+	output, err := d.runOcr(img)
+	if err != nil {
+		return "", 0, err
+	}
+	defer output.Destroy()
+
+	text, err := d.decodeOcrOutput(output)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var probability [][]float32
+	switch t := output.(type) {
+	case *ort.Tensor[float32]:
+		probability = ctcProbability(t.GetData(), t.GetShape(), d.outputLayout, d.outputActivation)
+	default:
+		// 预 argmax 输出没有概率信息
+		return text, 1, nil
+	}
+
+	return text, ctcConfidence(probability, d.allowedIndices), nil
+}
+
+// ctcConfidence 取 CTC 贪心解码中每个输出字符的峰值概率的平均值
+func ctcConfidence(probability [][]float32, allowedIndices []int) float32 {
+	var sum float32
+	count := 0
+	lastIdx := -1
+
+	for _, probs := range probability {
+		maxIdx := 0
+		if len(allowedIndices) > 0 {
+			maxIdx = allowedIndices[0]
+			for _, c := range allowedIndices {
+				if c < len(probs) && probs[c] > probs[maxIdx] {
+					maxIdx = c
+				}
+			}
+		} else {
+			for c := 1; c < len(probs); c++ {
+				if probs[c] > probs[maxIdx] {
+					maxIdx = c
+				}
+			}
+		}
+
+		if maxIdx != 0 && maxIdx != lastIdx {
+			sum += probs[maxIdx]
+			count++
+		}
+		lastIdx = maxIdx
+	}
+
+	if count == 0 {
+		return 0
+	}
+	return sum / float32(count)
+}
+
+// readingOrder 按阅读顺序（先行后列）排列检测框
+//
+// 垂直中心相差不超过框高中位数一半的框视为同一行。
+func readingOrder(dets []Detection) []Detection {
+	if len(dets) <= 1 {
+		return dets
+	}
+
+	heights := make([]int, len(dets))
+	for i, det := range dets {
+		heights[i] = det.Box.Y2 - det.Box.Y1
+	}
+	sort.Ints(heights)
+	lineTol := float64(heights[len(heights)/2]) / 2
+
+	sorted := append([]Detection(nil), dets...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Box.Y1+sorted[i].Box.Y2 < sorted[j].Box.Y1+sorted[j].Box.Y2
+	})
+
+	var lines [][]Detection
+	var lineCenter float64
+	for _, det := range sorted {
+		center := float64(det.Box.Y1+det.Box.Y2) / 2
+		if len(lines) == 0 || center-lineCenter > lineTol {
+			lines = append(lines, nil)
+			lineCenter = center
+		}
+		lines[len(lines)-1] = append(lines[len(lines)-1], det)
+	}
+
+	result := make([]Detection, 0, len(dets))
+	for _, line := range lines {
+		sort.SliceStable(line, func(i, j int) bool {
+			return line[i].Box.X1 < line[j].Box.X1
+		})
+		result = append(result, line...)
+	}
+	return result
+}
+
+// padImage 在图片四周补白
+func padImage(img image.Image, padding int) image.Image {
+	if padding <= 0 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	result := image.NewRGBA(image.Rect(0, 0, bounds.Dx()+2*padding, bounds.Dy()+2*padding))
+	draw.Draw(result, result.Bounds(), &image.Uniform{color.White}, image.Point{}, draw.Src)
+	draw.Draw(result, image.Rect(padding, padding, padding+bounds.Dx(), padding+bounds.Dy()), img, bounds.Min, draw.Src)
+	return result
+}
@@ -0,0 +1,145 @@
+package ddddocr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// 乱序背景图还原
+// ============================================================================
+
+// Tile 还原用的图块：将精灵图中 (SrcX, SrcY) 起的 W×H 区域复制到结果图的 (DstX, DstY)
+type Tile struct {
+	SrcX, SrcY int
+	DstX, DstY int
+	W, H       int
+}
+
+// ReassembleLayout 图块布局
+type ReassembleLayout struct {
+	Tiles  []Tile
+	Width  int // 结果图宽度，0 表示由图块推算
+	Height int // 结果图高度，0 表示由图块推算
+}
+
+// StripLayout 竖条布局：精灵图由等宽竖条组成，order[i] 为结果图第 i 条对应的精灵图竖条下标
+func StripLayout(order []int, stripWidth, height int) ReassembleLayout {
+	tiles := make([]Tile, len(order))
+	for i, src := range order {
+		tiles[i] = Tile{
+			SrcX: src * stripWidth,
+			DstX: i * stripWidth,
+			W:    stripWidth,
+			H:    height,
+		}
+	}
+	return ReassembleLayout{Tiles: tiles, Width: len(order) * stripWidth, Height: height}
+}
+
+// OffsetLayout 位置列表布局：结果图按行优先由 cols 列 tileW×tileH 的图块组成，
+// positions[i] 为第 i 块在精灵图中的位置
+//
+// 位置可直接使用 CSS background-position 的值，负数按绝对值处理。
+func OffsetLayout(positions []image.Point, tileW, tileH, cols int) ReassembleLayout {
+	if cols <= 0 {
+		cols = len(positions)
+	}
+	abs := func(v int) int {
+		if v < 0 {
+			return -v
+		}
+		return v
+	}
+
+	tiles := make([]Tile, len(positions))
+	for i, p := range positions {
+		tiles[i] = Tile{
+			SrcX: abs(p.X),
+			SrcY: abs(p.Y),
+			DstX: (i % cols) * tileW,
+			DstY: (i / cols) * tileH,
+			W:    tileW,
+			H:    tileH,
+		}
+	}
+
+	rows := (len(positions) + cols - 1) / max(cols, 1)
+	return ReassembleLayout{Tiles: tiles, Width: min(cols, len(positions)) * tileW, Height: rows * tileH}
+}
+
+// ParseBackgroundPosition 解析 CSS background-position，如 "-157px -58px"，小数四舍五入到整数像素
+func ParseBackgroundPosition(s string) (image.Point, error) {
+	fields := strings.Fields(strings.TrimSpace(s))
+	if len(fields) != 2 {
+		return image.Point{}, fmt.Errorf("无效的 background-position: %q", s)
+	}
+
+	var coords [2]int
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(f, "px"), 64)
+		if err != nil {
+			return image.Point{}, fmt.Errorf("无效的 background-position: %q", s)
+		}
+		coords[i] = int(math.Round(v))
+	}
+	return image.Point{X: coords[0], Y: coords[1]}, nil
+}
+
+// Reassemble 按布局把乱序的精灵图还原为完整背景图
+func Reassemble(spriteBytes []byte, layout ReassembleLayout) (*image.RGBA, error) {
+	sprite, _, err := image.Decode(bytes.NewReader(spriteBytes))
+	if err != nil {
+		return nil, fmt.Errorf("解码精灵图失败: %w", err)
+	}
+	return reassembleImage(sprite, layout)
+}
+
+// ReassemblePNG 还原背景图并编码为 PNG，可直接传给 SlideMatch 等滑块接口
+func ReassemblePNG(spriteBytes []byte, layout ReassembleLayout) ([]byte, error) {
+	img, err := Reassemble(spriteBytes, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("编码还原图失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// reassembleImage 按布局复制图块
+func reassembleImage(sprite image.Image, layout ReassembleLayout) (*image.RGBA, error) {
+	if len(layout.Tiles) == 0 {
+		return nil, fmt.Errorf("布局中没有图块")
+	}
+
+	bounds := sprite.Bounds()
+	width, height := layout.Width, layout.Height
+	for i, t := range layout.Tiles {
+		src := image.Rect(t.SrcX, t.SrcY, t.SrcX+t.W, t.SrcY+t.H)
+		if t.W <= 0 || t.H <= 0 || !src.Add(bounds.Min).In(bounds) {
+			return nil, fmt.Errorf("第 %d 个图块 %v 超出精灵图范围 %dx%d", i, src, bounds.Dx(), bounds.Dy())
+		}
+		if layout.Width <= 0 {
+			width = max(width, t.DstX+t.W)
+		}
+		if layout.Height <= 0 {
+			height = max(height, t.DstY+t.H)
+		}
+	}
+
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+	for _, t := range layout.Tiles {
+		dst := image.Rect(t.DstX, t.DstY, t.DstX+t.W, t.DstY+t.H)
+		draw.Draw(result, dst, sprite, bounds.Min.Add(image.Pt(t.SrcX, t.SrcY)), draw.Src)
+	}
+	return result, nil
+}
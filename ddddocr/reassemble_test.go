@@ -0,0 +1,70 @@
+package ddddocr
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestParseBackgroundPosition(t *testing.T) {
+	tests := []struct {
+		in   string
+		want image.Point
+		ok   bool
+	}{
+		{"-157px -58px", image.Pt(-157, -58), true},
+		{" -145px 0px ", image.Pt(-145, 0), true},
+		{"-12.6px -57.5px", image.Pt(-13, -58), true},
+		{"12.4px 0", image.Pt(12, 0), true},
+		{"-157px", image.Point{}, false},
+		{"left top", image.Point{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseBackgroundPosition(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Fatalf("ParseBackgroundPosition(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestOffsetLayoutReassemble(t *testing.T) {
+	// 精灵图每个 12x58 列块中取 x 偏移 1 起的 10x58 区域，颜色编码块下标
+	sprite := image.NewRGBA(image.Rect(0, 0, 48, 116))
+	for y := 0; y < 116; y++ {
+		for x := 0; x < 48; x++ {
+			sprite.SetRGBA(x, y, color.RGBA{R: uint8(x / 12), G: uint8(y / 58), A: 255})
+		}
+	}
+
+	var positions []image.Point
+	for _, s := range []string{"-37px -58px", "-1px 0px", "-25px 0px", "-13px -58px"} {
+		p, err := ParseBackgroundPosition(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		positions = append(positions, p)
+	}
+	layout := OffsetLayout(positions, 10, 58, 2)
+	if layout.Width != 20 || layout.Height != 116 {
+		t.Fatalf("结果图尺寸 %dx%d, want 20x116", layout.Width, layout.Height)
+	}
+
+	img, err := reassembleImage(sprite, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]uint8{{3, 1}, {0, 0}, {2, 0}, {1, 1}}
+	for i, w := range want {
+		x, y := (i%2)*10, (i/2)*58
+		for _, p := range []image.Point{{x, y}, {x + 9, y + 57}} {
+			if c := img.RGBAAt(p.X, p.Y); c.R != w[0] || c.G != w[1] {
+				t.Fatalf("图块 %d 在 %v 的颜色 %v, want R=%d G=%d", i, p, c, w[0], w[1])
+			}
+		}
+	}
+
+	layout.Tiles[0].SrcX = 40
+	if _, err := reassembleImage(sprite, layout); err == nil {
+		t.Fatal("图块超出精灵图范围时应返回错误")
+	}
+}
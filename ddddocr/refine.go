@@ -0,0 +1,107 @@
+package ddddocr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+)
+
+// ============================================================================
+// 检测框精修
+// ============================================================================
+
+// RefineOptions 检测框精修选项
+type RefineOptions struct {
+	Margin      int     // 搜索窗口向外扩展的像素
+	MarginRatio float64 // 按框短边比例扩展，与 Margin 取较大者，默认 0.15
+}
+
+// RefinedBox 精修结果
+type RefinedBox struct {
+	Box     BBox    `json:"box"`     // 贴合前景像素的框
+	InkX    float64 `json:"ink_x"`   // 前景像素质心，比框中心更适合作为点击位置
+	InkY    float64 `json:"ink_y"`   //
+	Refined bool    `json:"refined"` // 未找到前景时为 false，Box 与质心取原框
+}
+
+// RefineBoxes 将检测框重新贴合到框内的前景像素（墨迹）
+//
+// 在外扩窗口内做 Otsu 二值化和连通域分析，保留质心落在原框内的连通域，
+// 以它们的外接框作为新框。
+func RefineBoxes(imageData []byte, boxes []BBox, opts RefineOptions) ([]RefinedBox, error) {
+	img, _, err := image.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("解码失败: %w", err)
+	}
+	return refineBoxes(img, boxes, opts), nil
+}
+
+// refineBoxes 对已解码的图片精修检测框
+func refineBoxes(img image.Image, boxes []BBox, opts RefineOptions) []RefinedBox {
+	if opts.MarginRatio <= 0 && opts.Margin <= 0 {
+		opts.MarginRatio = 0.15
+	}
+
+	gray, w, h := grayPixels(img)
+	results := make([]RefinedBox, len(boxes))
+	for i, box := range boxes {
+		results[i] = refineBox(gray, w, h, box, opts)
+	}
+	return results
+}
+
+// refineBox 精修单个框
+func refineBox(gray []uint8, w, h int, box BBox, opts RefineOptions) RefinedBox {
+	result := RefinedBox{
+		Box:  box,
+		InkX: float64(box.X1+box.X2) / 2,
+		InkY: float64(box.Y1+box.Y2) / 2,
+	}
+
+	margin := max(opts.Margin, 2, int(float64(min(box.X2-box.X1, box.Y2-box.Y1))*opts.MarginRatio+0.5))
+	win := image.Rect(box.X1-margin, box.Y1-margin, box.X2+margin, box.Y2+margin).Intersect(image.Rect(0, 0, w, h))
+	if win.Dx() < 3 || win.Dy() < 3 {
+		return result
+	}
+
+	ww, wh := win.Dx(), win.Dy()
+	sub := make([]uint8, ww*wh)
+	for y := 0; y < wh; y++ {
+		copy(sub[y*ww:(y+1)*ww], gray[(y+win.Min.Y)*w+win.Min.X:(y+win.Min.Y)*w+win.Max.X])
+	}
+
+	mask := foregroundMask(sub, ww, wh)
+	minArea := max(3, ww*wh/500)
+
+	refined := BBox{X1: w, Y1: h}
+	var sumX, sumY float64
+	total := 0
+	for _, comp := range connectedComponents(mask, ww, wh) {
+		if comp.Area < minArea {
+			continue
+		}
+		cx := comp.CX + float64(win.Min.X)
+		cy := comp.CY + float64(win.Min.Y)
+		if cx < float64(box.X1) || cx >= float64(box.X2) || cy < float64(box.Y1) || cy >= float64(box.Y2) {
+			continue
+		}
+
+		refined.X1 = min(refined.X1, comp.Box.X1+win.Min.X)
+		refined.Y1 = min(refined.Y1, comp.Box.Y1+win.Min.Y)
+		refined.X2 = max(refined.X2, comp.Box.X2+win.Min.X)
+		refined.Y2 = max(refined.Y2, comp.Box.Y2+win.Min.Y)
+		sumX += cx * float64(comp.Area)
+		sumY += cy * float64(comp.Area)
+		total += comp.Area
+	}
+
+	if total == 0 {
+		return result
+	}
+
+	result.Box = refined
+	result.InkX = sumX / float64(total)
+	result.InkY = sumY / float64(total)
+	result.Refined = true
+	return result
+}
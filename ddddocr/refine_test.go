@@ -0,0 +1,76 @@
+package ddddocr
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// glyphImage 白底上的黑色 "L" 形字形，外接框 [30, 20, 60, 60]
+func glyphImage() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 120, 80))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	fill := func(x1, y1, x2, y2 int) {
+		for y := y1; y < y2; y++ {
+			for x := x1; x < x2; x++ {
+				img.Pix[y*img.Stride+x] = 20
+			}
+		}
+	}
+	fill(30, 20, 36, 60) // 竖笔
+	fill(36, 54, 60, 60) // 横笔
+	return img
+}
+
+func TestRefineBoxes(t *testing.T) {
+	glyph := BBox{X1: 30, Y1: 20, X2: 60, Y2: 60}
+	tests := []struct {
+		name string
+		box  BBox
+	}{
+		{"宽松框收紧", BBox{X1: 20, Y1: 10, X2: 75, Y2: 70}},
+		{"截断框扩展", BBox{X1: 32, Y1: 22, X2: 58, Y2: 58}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := RefineBoxes(encodeTestPNG(t, glyphImage()), []BBox{tt.box}, RefineOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := results[0]
+			if !r.Refined || r.Box != glyph {
+				t.Fatalf("RefineBoxes(%v) = %+v, want %v", tt.box, r, glyph)
+			}
+		})
+	}
+}
+
+func TestRefineBoxInkCentroid(t *testing.T) {
+	gray, w, h := grayPixels(glyphImage())
+	box := BBox{X1: 20, Y1: 10, X2: 75, Y2: 70}
+	r := refineBox(gray, w, h, box, RefineOptions{MarginRatio: 0.15})
+
+	// 竖笔 240 像素、质心 (32.5, 39.5)，横笔 144 像素、质心 (47.5, 56.5)
+	wantX := (240*32.5 + 144*47.5) / 384
+	wantY := (240*39.5 + 144*56.5) / 384
+	if math.Abs(r.InkX-wantX) > 1e-9 || math.Abs(r.InkY-wantY) > 1e-9 {
+		t.Fatalf("质心 (%.3f, %.3f), want (%.3f, %.3f)", r.InkX, r.InkY, wantX, wantY)
+	}
+	if cx := float64(r.Box.X1+r.Box.X2) / 2; math.Abs(r.InkX-cx) < 5 {
+		t.Fatalf("质心 %.3f 不应与框中心 %.3f 重合", r.InkX, cx)
+	}
+}
+
+func TestRefineBoxEmptyWindow(t *testing.T) {
+	gray, w, h := grayPixels(glyphImage())
+	box := BBox{X1: 80, Y1: 5, X2: 110, Y2: 30}
+	r := refineBox(gray, w, h, box, RefineOptions{MarginRatio: 0.15})
+
+	want := RefinedBox{Box: box, InkX: 95, InkY: 17.5}
+	if r != want {
+		t.Fatalf("refineBox = %+v, want %+v", r, want)
+	}
+}
@@ -0,0 +1,202 @@
+package ddddocr
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+)
+
+// ============================================================================
+// 图像配准（相位相关）
+// ============================================================================
+
+// SlideTransform 配准得到的变换：背景图以左上角为原点缩放 Scale 倍后平移 (DX, DY)，与目标图对齐
+type SlideTransform struct {
+	DX      float64 `json:"dx"`
+	DY      float64 `json:"dy"`
+	Scale   float64 `json:"scale"`
+	Peak    float64 `json:"peak"`    // 相位相关峰值 (0, 1]，越大越可信
+	Applied bool    `json:"applied"` // 是否对背景图做了变换；两图已对齐时为 false，直接差分
+}
+
+// 配准的缩放搜索范围与步长，以及应用变换所需的最低相位相关峰值
+const (
+	alignScaleRange = 0.1
+	alignScaleStep  = 0.01
+	minAlignPeak    = 0.05
+)
+
+// estimateTransform 估计背景图到目标图的平移（及可选的缩放）
+//
+// 两图尺寸不同时以宽度比作为基准缩放；withScale 为 true 时在基准的 [1-0.1, 1+0.1] 倍内
+// 按 0.01 步长搜索缩放比例，取相位相关峰值最高者。
+func estimateTransform(target, background image.Image, withScale bool) SlideTransform {
+	tpl := grayMatchPlane(target)
+	bg := grayMatchPlane(background)
+
+	base := float64(tpl.w) / float64(bg.w)
+	scaled := bg
+	if base != 1 {
+		scaled = scalePlane(bg, base, tpl.w, tpl.h)
+	}
+	best := SlideTransform{Scale: base}
+	best.DX, best.DY, best.Peak = phaseCorrelate(tpl, scaled)
+	if !withScale {
+		return best
+	}
+
+	steps := int(math.Round(alignScaleRange / alignScaleStep))
+	for i := -steps; i <= steps; i++ {
+		if i == 0 {
+			continue
+		}
+		s := base * (1 + float64(i)*alignScaleStep)
+		scaled := scalePlane(bg, s, tpl.w, tpl.h)
+		dx, dy, peak := phaseCorrelate(tpl, scaled)
+		if peak > best.Peak {
+			best = SlideTransform{DX: dx, DY: dy, Scale: s, Peak: peak}
+		}
+	}
+	return best
+}
+
+// phaseCorrelate 相位相关估计平移：返回 (dx, dy) 使 a(x, y) ≈ b(x-dx, y-dy)，以及峰值
+//
+// 两图先乘 Hann 窗抑制边界效应，峰值位置用抛物线拟合到亚像素。
+func phaseCorrelate(a, b matchPlane) (float64, float64, float64) {
+	w, h := min(a.w, b.w), min(a.h, b.h)
+	fw, fh := nextPow2(w), nextPow2(h)
+
+	fa := make([]complex128, fw*fh)
+	fb := make([]complex128, fw*fh)
+	for y := 0; y < h; y++ {
+		wy := hannWeight(y, h)
+		for x := 0; x < w; x++ {
+			wxy := wy * hannWeight(x, w)
+			fa[y*fw+x] = complex(a.data[y*a.w+x]*wxy, 0)
+			fb[y*fw+x] = complex(b.data[y*b.w+x]*wxy, 0)
+		}
+	}
+	fft2D(fa, fw, fh, false)
+	fft2D(fb, fw, fh, false)
+
+	// 归一化互功率谱
+	for i := range fa {
+		c := fa[i] * cmplx.Conj(fb[i])
+		if m := cmplx.Abs(c); m > 1e-12 {
+			fa[i] = c / complex(m, 0)
+		} else {
+			fa[i] = 0
+		}
+	}
+	fft2D(fa, fw, fh, true)
+
+	peakIdx := 0
+	for i := range fa {
+		if real(fa[i]) > real(fa[peakIdx]) {
+			peakIdx = i
+		}
+	}
+	px, py := peakIdx%fw, peakIdx/fw
+	at := func(x, y int) float64 {
+		return real(fa[((y+fh)%fh)*fw+(x+fw)%fw])
+	}
+
+	dx := float64(px) + parabolicOffset(at(px-1, py), at(px, py), at(px+1, py))
+	dy := float64(py) + parabolicOffset(at(px, py-1), at(px, py), at(px, py+1))
+
+	// 超过一半的位移视为负方向（循环卷积）
+	if dx > float64(fw)/2 {
+		dx -= float64(fw)
+	}
+	if dy > float64(fh)/2 {
+		dy -= float64(fh)
+	}
+	return dx, dy, at(px, py)
+}
+
+// hannWeight Hann 窗系数
+func hannWeight(i, n int) float64 {
+	if n <= 1 {
+		return 1
+	}
+	return 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+}
+
+// parabolicOffset 三点抛物线拟合的峰值偏移，范围 [-0.5, 0.5]
+func parabolicOffset(left, center, right float64) float64 {
+	denom := left - 2*center + right
+	if denom == 0 {
+		return 0
+	}
+	return math.Max(-0.5, math.Min(0.5, 0.5*(left-right)/denom))
+}
+
+// scalePlane 以左上角为原点缩放平面（双线性插值），输出 w×h，超出原图的部分取边缘值
+func scalePlane(p matchPlane, scale float64, w, h int) matchPlane {
+	data := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			data[y*w+x] = bilinearPlane(p, float64(x)/scale, float64(y)/scale)
+		}
+	}
+	return matchPlane{data: data, w: w, h: h}
+}
+
+// bilinearPlane 双线性采样，坐标截断到平面范围内
+func bilinearPlane(p matchPlane, u, v float64) float64 {
+	u = math.Max(0, math.Min(float64(p.w-1), u))
+	v = math.Max(0, math.Min(float64(p.h-1), v))
+	x0, y0 := int(u), int(v)
+	x1, y1 := min(x0+1, p.w-1), min(y0+1, p.h-1)
+	fx, fy := u-float64(x0), v-float64(y0)
+
+	top := p.data[y0*p.w+x0]*(1-fx) + p.data[y0*p.w+x1]*fx
+	bottom := p.data[y1*p.w+x0]*(1-fx) + p.data[y1*p.w+x1]*fx
+	return top*(1-fy) + bottom*fy
+}
+
+// warpToTarget 按变换把背景图重采样到目标图坐标系
+//
+// 落在背景图之外的像素直接取目标图的值，使其差分为 0。
+func warpToTarget(background, target image.Image, t SlideTransform) *image.RGBA {
+	tb := target.Bounds()
+	w, h := tb.Dx(), tb.Dy()
+	bb := background.Bounds()
+	bw, bh := bb.Dx(), bb.Dy()
+
+	r, g, b := rgbPlanes(background)
+	planes := []matchPlane{
+		{data: int32ToFloat64(r), w: bw, h: bh},
+		{data: int32ToFloat64(g), w: bw, h: bh},
+		{data: int32ToFloat64(b), w: bw, h: bh},
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			u := (float64(x) - t.DX) / t.Scale
+			v := (float64(y) - t.DY) / t.Scale
+			if u < 0 || v < 0 || u > float64(bw-1) || v > float64(bh-1) {
+				out.Set(x, y, target.At(x+tb.Min.X, y+tb.Min.Y))
+				continue
+			}
+
+			var c [3]uint8
+			for i, p := range planes {
+				c[i] = uint8(math.Round(math.Max(0, math.Min(255, bilinearPlane(p, u, v)))))
+			}
+			out.SetRGBA(x, y, color.RGBA{c[0], c[1], c[2], 255})
+		}
+	}
+	return out
+}
+
+func int32ToFloat64(data []int32) []float64 {
+	result := make([]float64, len(data))
+	for i, v := range data {
+		result[i] = float64(v)
+	}
+	return result
+}
@@ -0,0 +1,146 @@
+package ddddocr
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/nfnt/resize"
+)
+
+// loadComparisonPair 读取 testimg 中带缺口的背景图与完整背景图
+func loadComparisonPair(t *testing.T) ([]byte, image.Image) {
+	t.Helper()
+	gapBytes, err := os.ReadFile("../testimg/bgImage.png")
+	if err != nil {
+		t.Skipf("缺少测试图片: %v", err)
+	}
+	fullBytes, err := os.ReadFile("../testimg/滑块完整背景图.jpg")
+	if err != nil {
+		t.Skipf("缺少测试图片: %v", err)
+	}
+	full, _, err := image.Decode(bytes.NewReader(fullBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gapBytes, full
+}
+
+// shiftImage 平移图像，移出画布的部分丢弃，空出的部分复制边缘
+func shiftImage(img image.Image, dx, dy int) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			sx := max(0, min(b.Dx()-1, x-dx))
+			sy := max(0, min(b.Dy()-1, y-dy))
+			out.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return out
+}
+
+var alignTestOptions = SlideComparisonOptions{Threshold: 30, BlurSize: 5, MorphRadius: 3, Align: true}
+
+func checkBox(t *testing.T, got []int, want []int, tol int) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Box = %v, want %v", got, want)
+	}
+	for i := range want {
+		if d := got[i] - want[i]; d < -tol || d > tol {
+			t.Fatalf("Box = %v, want %v ±%d", got, want, tol)
+		}
+	}
+}
+
+func TestSlideComparisonAlignShift(t *testing.T) {
+	gapBytes, full := loadComparisonPair(t)
+	d := &DdddOcr{}
+
+	aligned, err := d.SlideComparisonWithOptions(gapBytes, encodeTestPNG(t, full), alignTestOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := aligned.Box
+
+	// 内容左移 4、下移 3，背景图需右移 4、上移 3 才能与缺口图对齐
+	result, err := d.SlideComparisonWithOptions(gapBytes, encodeTestPNG(t, shiftImage(full, -4, 3)), alignTestOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := result.Transform
+	if !tr.Applied || math.Abs(tr.DX-4) > 0.25 || math.Abs(tr.DY+3) > 0.25 || tr.Scale != 1 {
+		t.Fatalf("Transform = %+v, want (4, -3)", *tr)
+	}
+	checkBox(t, result.Box, want, 1)
+}
+
+func TestSlideComparisonAlignScale(t *testing.T) {
+	gapBytes, full := loadComparisonPair(t)
+	d := &DdddOcr{}
+
+	aligned, err := d.SlideComparisonWithOptions(gapBytes, encodeTestPNG(t, full), alignTestOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 完整图放大 5%：配准使用原始尺寸的背景图，缩放应接近 20/21
+	b := full.Bounds()
+	scaled := resize.Resize(uint(b.Dx()*21/20), uint(b.Dy()*21/20), full, resize.Bilinear)
+
+	opts := alignTestOptions
+	opts.AlignScale = true
+	result, err := d.SlideComparisonWithOptions(gapBytes, encodeTestPNG(t, scaled), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := result.Transform
+	if !tr.Applied || math.Abs(tr.Scale-20.0/21) > 0.01 || math.Abs(tr.DX) > 1 || math.Abs(tr.DY) > 1 {
+		t.Fatalf("Transform = %+v, want scale %.3f", *tr, 20.0/21)
+	}
+	checkBox(t, result.Box, aligned.Box, 4)
+}
+
+func TestSlideComparisonAlignFallback(t *testing.T) {
+	gapBytes, full := loadComparisonPair(t)
+	d := &DdddOcr{}
+
+	// 两图已对齐：不做变换，结果与未开启 Align 一致
+	plain := alignTestOptions
+	plain.Align = false
+	base, err := d.SlideComparisonWithOptions(gapBytes, encodeTestPNG(t, full), plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := d.SlideComparisonWithOptions(gapBytes, encodeTestPNG(t, full), alignTestOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Transform.Applied {
+		t.Fatalf("已对齐的图片不应变换: %+v", *result.Transform)
+	}
+	checkBox(t, result.Box, base.Box, 0)
+
+	// 与缺口图无关的噪声图：峰值过低，不做变换
+	rng := rand.New(rand.NewSource(1))
+	b := full.Bounds()
+	noise := image.NewRGBA(image.Rect(0, 0, b.Dx()+10, b.Dy()+10))
+	draw.Draw(noise, noise.Rect, image.Black, image.Point{}, draw.Src)
+	for i := range noise.Pix {
+		if i%4 != 3 {
+			noise.Pix[i] = uint8(rng.Intn(256))
+		}
+	}
+	result, err = d.SlideComparisonWithOptions(gapBytes, encodeTestPNG(t, noise), alignTestOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Transform.Applied {
+		t.Fatalf("峰值过低时不应变换: %+v", *result.Transform)
+	}
+}
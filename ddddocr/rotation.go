@@ -0,0 +1,338 @@
+package ddddocr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+)
+
+// ============================================================================
+// 旋转验证码
+// ============================================================================
+
+// RotationOptions 旋转验证码选项
+type RotationOptions struct {
+	Step        float64 // 角度搜索步长（度），0 表示 1
+	Band        int     // 边界两侧的采样带宽（像素），0 表示 4
+	InnerRadius float64 // 内圆半径（像素），0 表示由透明通道或图像尺寸推算
+	OuterRadius float64 // 外环图上内圆边界所在的半径（像素），0 表示由中心透明区域推算，无透明区域时等于内圆半径
+}
+
+// RotationResult 旋转验证码结果
+type RotationResult struct {
+	Angle      float64 `json:"angle"`      // 将内圆顺时针旋转该角度（度，[0, 360)）即可摆正
+	Confidence float64 `json:"confidence"` // 置信度 [0, 1]
+}
+
+// rotationMinSeparation 计算次优角度时与最优角度的最小间隔（度）
+const rotationMinSeparation = 10
+
+// SolveRotation 求解旋转验证码
+//
+// inner 为被旋转的圆形图片，outer 为以内圆为中心的外环背景图；outer 为空时
+// 使用单图模式，按梯度方向先验估计角度。
+func (d *DdddOcr) SolveRotation(innerBytes, outerBytes []byte) (*RotationResult, error) {
+	return d.SolveRotationWithOptions(innerBytes, outerBytes, RotationOptions{})
+}
+
+// SolveRotationWithOptions 使用自定义选项求解旋转验证码
+//
+// 双图模式在各候选角度下比较内圆边界内侧与外环边界外侧的像素，取相关性最高的角度。
+func (d *DdddOcr) SolveRotationWithOptions(innerBytes, outerBytes []byte, opts RotationOptions) (*RotationResult, error) {
+	inner, _, err := image.Decode(bytes.NewReader(innerBytes))
+	if err != nil {
+		return nil, fmt.Errorf("解码内圆图失败: %w", err)
+	}
+
+	step := opts.Step
+	if step <= 0 {
+		step = 1
+	}
+	band := opts.Band
+	if band <= 0 {
+		band = 4
+	}
+	radius := opts.InnerRadius
+	if radius <= 0 {
+		radius = circleRadius(inner)
+	}
+	if radius <= float64(band)+1 {
+		return nil, fmt.Errorf("内圆半径过小: %.1f", radius)
+	}
+
+	if len(outerBytes) == 0 {
+		return rotationFromPrior(inner, radius), nil
+	}
+
+	outer, _, err := image.Decode(bytes.NewReader(outerBytes))
+	if err != nil {
+		return nil, fmt.Errorf("解码外环图失败: %w", err)
+	}
+	outerRadius := opts.OuterRadius
+	if outerRadius <= 0 {
+		outerRadius = holeRadius(outer)
+	}
+	if outerRadius <= 0 {
+		outerRadius = radius
+	}
+
+	bins := max(int(math.Round(360/step)), 8)
+	innerRing := ringSignal(inner, radius-float64(band), radius-1, bins)
+	outerRing := ringSignal(outer, outerRadius+1, outerRadius+float64(band), bins)
+
+	scores := circularCorrelation(innerRing, outerRing, bins)
+	return rotationFromScores(scores, 360/float64(bins)), nil
+}
+
+// circleRadius 由透明通道估计圆的半径（不透明像素面积 = πr²），无透明像素时取短边的一半
+func circleRadius(img image.Image) float64 {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	opaque := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a > 0x7fff {
+				opaque++
+			}
+		}
+	}
+	if opaque == 0 || opaque == w*h {
+		return float64(min(w, h)) / 2
+	}
+	return math.Sqrt(float64(opaque) / math.Pi)
+}
+
+// holeRadius 由外环图中心的透明区域估计内圆边界半径（透明像素面积 = πr²）
+//
+// 从中心像素出发按 4 邻域填充透明像素；中心不透明或透明区域连到图像边界时返回 0。
+func holeRadius(img image.Image) float64 {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return 0
+	}
+
+	transparent := make([]bool, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			_, _, _, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			transparent[y*w+x] = a <= 0x7fff
+		}
+	}
+
+	start := (h/2)*w + w/2
+	if !transparent[start] {
+		return 0
+	}
+	visited := make([]bool, w*h)
+	visited[start] = true
+	stack := []int{start}
+	area := 0
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		area++
+
+		x, y := i%w, i/w
+		if x == 0 || y == 0 || x == w-1 || y == h-1 {
+			return 0
+		}
+		for _, n := range []int{i - 1, i + 1, i - w, i + w} {
+			if transparent[n] && !visited[n] {
+				visited[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	return math.Sqrt(float64(area) / math.Pi)
+}
+
+// ringSignal 以图像中心为圆心，在 [r1, r2] 环带内按角度分箱采样 RGB 均值
+//
+// 返回长度 3·bins 的信号，第 i 个角度箱为 [3i, 3i+3)。角度按图像坐标顺时针增加。
+func ringSignal(img image.Image, r1, r2 float64, bins int) []float64 {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	cx, cy := float64(w-1)/2, float64(h-1)/2
+
+	r, g, b := rgbPlanes(img)
+	planes := []matchPlane{
+		{data: int32ToFloat64(r), w: w, h: h},
+		{data: int32ToFloat64(g), w: w, h: h},
+		{data: int32ToFloat64(b), w: w, h: h},
+	}
+
+	radii := max(int(r2-r1)+1, 1)
+	signal := make([]float64, 3*bins)
+	for i := 0; i < bins; i++ {
+		theta := 2 * math.Pi * float64(i) / float64(bins)
+		cos, sin := math.Cos(theta), math.Sin(theta)
+		for k := 0; k < radii; k++ {
+			rad := r1 + (r2-r1)*float64(k)/float64(max(radii-1, 1))
+			x, y := cx+rad*cos, cy+rad*sin
+			for c, p := range planes {
+				signal[3*i+c] += bilinearPlane(p, x, y) / float64(radii)
+			}
+		}
+	}
+	return signal
+}
+
+// circularCorrelation 计算内圆信号顺时针旋转 k 个角度箱后与外环信号的 NCC
+func circularCorrelation(inner, outer []float64, bins int) []float64 {
+	center := func(s []float64) []float64 {
+		var mean float64
+		for _, v := range s {
+			mean += v
+		}
+		mean /= float64(len(s))
+
+		out := make([]float64, len(s))
+		var norm float64
+		for i, v := range s {
+			out[i] = v - mean
+			norm += out[i] * out[i]
+		}
+		norm = math.Sqrt(norm)
+		if norm > 0 {
+			for i := range out {
+				out[i] /= norm
+			}
+		}
+		return out
+	}
+	a, b := center(inner), center(outer)
+
+	scores := make([]float64, bins)
+	for k := 0; k < bins; k++ {
+		var sum float64
+		for i := 0; i < bins; i++ {
+			// 旋转后角度 i 处的内容来自原内圆的角度 i-k
+			j := (i - k + bins) % bins
+			sum += a[3*j]*b[3*i] + a[3*j+1]*b[3*i+1] + a[3*j+2]*b[3*i+2]
+		}
+		scores[k] = sum
+	}
+	return scores
+}
+
+// rotationFromScores 取得分最高的角度（抛物线拟合到亚步长），并按与次优角度的差距计算置信度
+func rotationFromScores(scores []float64, step float64) *RotationResult {
+	n := len(scores)
+	best := 0
+	for k, v := range scores {
+		if v > scores[best] {
+			best = k
+		}
+	}
+
+	// 次优：与最优相距超过最小间隔的最高得分
+	runnerUp := math.Inf(-1)
+	for k, v := range scores {
+		dist := math.Abs(float64(k-best)) * step
+		dist = math.Min(dist, 360-dist)
+		if dist > rotationMinSeparation && v > runnerUp {
+			runnerUp = v
+		}
+	}
+
+	offset := parabolicOffset(scores[(best-1+n)%n], scores[best], scores[(best+1)%n])
+	angle := math.Mod((float64(best)+offset)*step+360, 360)
+
+	margin := scores[best] - runnerUp
+	if math.IsInf(runnerUp, -1) {
+		margin = 1
+	}
+	confidence := math.Max(0, math.Min(1, scores[best])) * math.Min(1, margin/0.1)
+	return &RotationResult{Angle: angle, Confidence: confidence}
+}
+
+// rotationFromPrior 单图模式：按梯度方向先验估计旋转角度
+//
+// 自然图像的边缘多为水平或竖直，梯度方向的 4 倍角平均给出模 90° 的偏转；
+// 再在 4 个候选方向中选取"上亮下暗"最明显的一个。置信度由方向集中程度与
+// 上下亮度差共同决定。
+func rotationFromPrior(img image.Image, radius float64) *RotationResult {
+	p := grayMatchPlane(img)
+	cx, cy := float64(p.w-1)/2, float64(p.h-1)/2
+	at := func(x, y int) float64 { return p.data[y*p.w+x] }
+
+	var sumCos, sumSin, sumMag float64
+	for y := 1; y < p.h-1; y++ {
+		for x := 1; x < p.w-1; x++ {
+			dx, dy := float64(x)-cx, float64(y)-cy
+			if dx*dx+dy*dy > (radius-2)*(radius-2) {
+				continue
+			}
+			gx := -at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1) +
+				at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1)
+			gy := -at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1) +
+				at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1)
+			mag := math.Hypot(gx, gy)
+			if mag < gradientMagThreshold {
+				continue
+			}
+			theta := math.Atan2(gy, gx)
+			sumCos += mag * math.Cos(4*theta)
+			sumSin += mag * math.Sin(4*theta)
+			sumMag += mag
+		}
+	}
+	if sumMag == 0 {
+		return &RotationResult{}
+	}
+
+	// 图像被顺时针旋转了 φ（模 90°），摆正需再旋转 -φ
+	phi := math.Atan2(sumSin, sumCos) / 4 * 180 / math.Pi
+	coherence := math.Hypot(sumCos, sumSin) / sumMag
+
+	bestAngle, bestScore, secondScore := 0.0, math.Inf(-1), math.Inf(-1)
+	for k := 0; k < 4; k++ {
+		angle := math.Mod(-phi+float64(k)*90+720, 360)
+		score := verticalBrightness(p, radius, angle)
+		if score > bestScore {
+			secondScore = bestScore
+			bestAngle, bestScore = angle, score
+		} else if score > secondScore {
+			secondScore = score
+		}
+	}
+
+	contrast := math.Min(1, (bestScore-secondScore)/20)
+	return &RotationResult{Angle: bestAngle, Confidence: coherence * contrast}
+}
+
+// verticalBrightness 内圆顺时针旋转 angle 度后，上半圆与下半圆的平均亮度差
+func verticalBrightness(p matchPlane, radius, angle float64) float64 {
+	cx, cy := float64(p.w-1)/2, float64(p.h-1)/2
+	rad := angle * math.Pi / 180
+	cos, sin := math.Cos(rad), math.Sin(rad)
+
+	var top, bottom float64
+	var nTop, nBottom int
+	r := int(radius) - 2
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if dx*dx+dy*dy > r*r || dy == 0 {
+				continue
+			}
+			// 旋转后 (dx, dy) 处的像素来自原图逆时针旋转 angle 的位置
+			sx := cx + float64(dx)*cos + float64(dy)*sin
+			sy := cy - float64(dx)*sin + float64(dy)*cos
+			v := bilinearPlane(p, sx, sy)
+			if dy < 0 {
+				top += v
+				nTop++
+			} else {
+				bottom += v
+				nBottom++
+			}
+		}
+	}
+	if nTop == 0 || nBottom == 0 {
+		return 0
+	}
+	return top/float64(nTop) - bottom/float64(nBottom)
+}
@@ -0,0 +1,118 @@
+package ddddocr
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// rotationTexture 以圆心为原点的彩色纹理，各方向互不对称
+func rotationTexture(x, y float64) color.NRGBA {
+	c := func(v float64) uint8 { return uint8(max(0, min(255, v))) }
+	return color.NRGBA{
+		R: c(128 + 60*math.Sin(x/9+1) + 40*math.Cos(y/13)),
+		G: c(128 + 50*math.Sin((x+2*y)/15)),
+		B: c(128 + 70*math.Cos((x-y)/11+0.5)),
+		A: 255,
+	}
+}
+
+// rotatedDisc 渲染半径 radius 的内圆：原图内容逆时针旋转 angle 度，圆外透明
+//
+// 顺时针旋转 angle 度即可摆正，即期望的 RotationResult.Angle。
+func rotatedDisc(radius int, angle float64, texture func(x, y float64) color.NRGBA) *image.NRGBA {
+	size := 2 * radius
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	rad := angle * math.Pi / 180
+	cos, sin := math.Cos(rad), math.Sin(rad)
+	c := float64(size-1) / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)-c, float64(y)-c
+			if dx*dx+dy*dy > float64(radius*radius) {
+				continue
+			}
+			// 内圆 (dx, dy) 处的内容来自原图顺时针旋转 angle 的位置
+			img.SetNRGBA(x, y, texture(dx*cos-dy*sin, dx*sin+dy*cos))
+		}
+	}
+	return img
+}
+
+// ringBackground 渲染外环背景图，中心留出半径 hole 的透明圆孔
+func ringBackground(size, hole int, texture func(x, y float64) color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	c := float64(size-1) / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)-c, float64(y)-c
+			if dx*dx+dy*dy <= float64(hole*hole) {
+				continue
+			}
+			img.SetNRGBA(x, y, texture(dx, dy))
+		}
+	}
+	return img
+}
+
+// angleDiff 两角度在圆周上的差（度）
+func angleDiff(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	return math.Min(d, 360-d)
+}
+
+func TestSolveRotationRing(t *testing.T) {
+	d := &DdddOcr{}
+	// 外环圆孔比内圆大几个像素，依赖由透明区域推算的 OuterRadius
+	outer := encodeTestPNG(t, ringBackground(200, 66, rotationTexture))
+
+	for _, angle := range []float64{0, 37, 90, 211} {
+		inner := encodeTestPNG(t, rotatedDisc(60, angle, rotationTexture))
+		result, err := d.SolveRotation(inner, outer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if angleDiff(result.Angle, angle) > 1 {
+			t.Fatalf("旋转 %.0f°: Angle = %.2f", angle, result.Angle)
+		}
+		if result.Confidence < 0.4 {
+			t.Fatalf("旋转 %.0f°: 置信度 %.3f 过低", angle, result.Confidence)
+		}
+	}
+}
+
+func TestHoleRadius(t *testing.T) {
+	if r := holeRadius(ringBackground(200, 66, rotationTexture)); math.Abs(r-66) > 1 {
+		t.Fatalf("holeRadius = %.2f, want 66", r)
+	}
+	if r := holeRadius(ringBackground(200, 0, rotationTexture)); r != 0 {
+		t.Fatalf("没有圆孔时 holeRadius = %.2f, want 0", r)
+	}
+}
+
+// sceneTexture 上亮下暗、带竖直条纹与水平地平线的场景
+func sceneTexture(x, y float64) color.NRGBA {
+	v := 200.0
+	if y > 8 {
+		v = 60
+	}
+	if math.Mod(math.Abs(x), 24) < 5 && y < 8 {
+		v -= 70
+	}
+	return color.NRGBA{R: uint8(v), G: uint8(v), B: uint8(v), A: 255}
+}
+
+func TestSolveRotationPrior(t *testing.T) {
+	d := &DdddOcr{}
+	for _, angle := range []float64{0, 37, 90, 211} {
+		inner := encodeTestPNG(t, rotatedDisc(60, angle, sceneTexture))
+		result, err := d.SolveRotation(inner, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if angleDiff(result.Angle, angle) > 3 {
+			t.Fatalf("旋转 %.0f°: Angle = %.2f", angle, result.Angle)
+		}
+	}
+}
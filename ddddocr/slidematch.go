@@ -0,0 +1,496 @@
+package ddddocr
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// ============================================================================
+// 滑块匹配方法
+// ============================================================================
+
+// 滑块匹配方法
+const (
+	SlideMethodEdge     = "edge"     // Canny 边缘图上的 NCC（默认）
+	SlideMethodGray     = "gray"     // 灰度图上的 NCC (TM_CCOEFF_NORMED)
+	SlideMethodSqDiff   = "sqdiff"   // 灰度图上的 TM_SQDIFF_NORMED，得分为 1 - 差异
+	SlideMethodGradient = "gradient" // 梯度方向一致性，对亮度与明暗极性不敏感
+	SlideMethodVote     = "vote"     // 以上四种方法得分图取平均（软投票）
+)
+
+// gradientMagThreshold 梯度方向匹配时忽略的弱梯度幅值（Sobel，0-255 灰度）
+const gradientMagThreshold = 40
+
+// matchPlane 单通道浮点图像
+type matchPlane struct {
+	data []float64
+	w, h int
+}
+
+// grayMatchPlane 图像转灰度平面
+func grayMatchPlane(img image.Image) matchPlane {
+	gray, w, h := grayPixels(img)
+	data := make([]float64, len(gray))
+	for i, v := range gray {
+		data[i] = float64(v)
+	}
+	return matchPlane{data: data, w: w, h: h}
+}
+
+// edgeMatchPlane Canny 边缘图转平面
+func edgeMatchPlane(img image.Image, opts CannyOptions) matchPlane {
+	edge := canny(img, opts)
+	w, h := edge.Bounds().Dx(), edge.Bounds().Dy()
+	data := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		row := edge.Pix[y*edge.Stride : y*edge.Stride+w]
+		for x, v := range row {
+			data[y*w+x] = float64(v)
+		}
+	}
+	return matchPlane{data: data, w: w, h: h}
+}
+
+// crop 裁剪平面，rect 必须位于平面内
+func (p matchPlane) crop(rect image.Rectangle) matchPlane {
+	w, h := rect.Dx(), rect.Dy()
+	data := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		src := (y+rect.Min.Y)*p.w + rect.Min.X
+		copy(data[y*w:(y+1)*w], p.data[src:src+w])
+	}
+	return matchPlane{data: data, w: w, h: h}
+}
+
+// slideScoreMap 按匹配方法计算搜索区域内的得分图（越大越好）
+//
+// 返回得分图及其宽高，得分为 -2 的位置无效；模板无纹理时得分图为 nil。
+func slideScoreMap(opts SlideMatchOptions, target, background image.Image, rect image.Rectangle) ([]float64, int, int, error) {
+	cannyOpts := DefaultCannyOptions()
+	if opts.Canny != nil {
+		cannyOpts = *opts.Canny
+		if err := validateCannyOptions(cannyOpts); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	// 掩码只对透明滑块有效
+	var mask []float64
+	if opts.Mask && !opts.SimpleTarget {
+		mask = alphaMaskWeights(target)
+	}
+
+	switch opts.Method {
+	case "", SlideMethodEdge:
+		bg, tpl := edgeMatchPlane(background, cannyOpts).crop(rect), edgeMatchPlane(target, cannyOpts)
+		scores, rw, rh := nccScoreMap(bg, tpl, mask)
+		return scores, rw, rh, nil
+	case SlideMethodGray:
+		bg, tpl := grayMatchPlane(background).crop(rect), grayMatchPlane(target)
+		scores, rw, rh := nccScoreMap(bg, tpl, mask)
+		return scores, rw, rh, nil
+	case SlideMethodSqDiff:
+		bg, tpl := grayMatchPlane(background).crop(rect), grayMatchPlane(target)
+		scores, rw, rh := sqdiffScoreMap(bg, tpl, mask)
+		return scores, rw, rh, nil
+	case SlideMethodGradient:
+		bg, tpl := grayMatchPlane(background), grayMatchPlane(target)
+		scores, rw, rh := gradientScoreMap(bg, tpl, rect, mask)
+		return scores, rw, rh, nil
+	case SlideMethodVote:
+		scores, rw, rh := voteScoreMap(target, background, rect, cannyOpts, mask)
+		return scores, rw, rh, nil
+	default:
+		return nil, 0, 0, fmt.Errorf("不支持的滑块匹配方法: %s", opts.Method)
+	}
+}
+
+// voteScoreMap 各方法得分图逐位置取平均
+func voteScoreMap(target, background image.Image, rect image.Rectangle, cannyOpts CannyOptions, mask []float64) ([]float64, int, int) {
+	bgGray, tplGray := grayMatchPlane(background), grayMatchPlane(target)
+	bgEdge, tplEdge := edgeMatchPlane(background, cannyOpts), edgeMatchPlane(target, cannyOpts)
+
+	scoreMaps := make([][]float64, 0, 4)
+	var rw, rh int
+	add := func(scores []float64, w, h int) {
+		if scores != nil {
+			scoreMaps = append(scoreMaps, scores)
+			rw, rh = w, h
+		}
+	}
+	add(nccScoreMap(bgEdge.crop(rect), tplEdge, mask))
+	add(nccScoreMap(bgGray.crop(rect), tplGray, mask))
+	add(sqdiffScoreMap(bgGray.crop(rect), tplGray, mask))
+	add(gradientScoreMap(bgGray, tplGray, rect, mask))
+
+	if len(scoreMaps) == 0 {
+		return nil, rw, rh
+	}
+	return averageScoreMaps(scoreMaps), rw, rh
+}
+
+// averageScoreMaps 逐位置对有效得分取平均
+//
+// 某个方法在某位置无效（-2，如窗口无纹理）时只跳过该方法，不否决其他方法；
+// 所有方法都无效的位置仍为 -2。
+func averageScoreMaps(scoreMaps [][]float64) []float64 {
+	sum := make([]float64, len(scoreMaps[0]))
+	count := make([]int, len(sum))
+	for _, scores := range scoreMaps {
+		for i, v := range scores {
+			if v <= -2 {
+				continue
+			}
+			sum[i] += v
+			count[i]++
+		}
+	}
+
+	for i := range sum {
+		if count[i] == 0 {
+			sum[i] = -2
+			continue
+		}
+		sum[i] /= float64(count[i])
+	}
+	return sum
+}
+
+// nccScoreMap 计算 TM_CCOEFF_NORMED 得分图
+//
+// 互相关用 FFT 计算，窗口均值与方差用积分图计算。窗口标准差过小的位置得分为 -2，
+// 模板标准差过小时返回 nil。mask 非空时改为带掩码的计算。
+func nccScoreMap(bg, tpl matchPlane, mask []float64) ([]float64, int, int) {
+	rw, rh := bg.w-tpl.w+1, bg.h-tpl.h+1
+	if rw <= 0 || rh <= 0 {
+		return nil, 0, 0
+	}
+	if mask != nil {
+		return maskedNCCScoreMap(bg, tpl, mask)
+	}
+	n := float64(tpl.w * tpl.h)
+
+	// 模板去均值
+	var mean, tplVar float64
+	for _, v := range tpl.data {
+		mean += v
+	}
+	mean /= n
+	centered := make([]float64, len(tpl.data))
+	for i, v := range tpl.data {
+		centered[i] = v - mean
+		tplVar += centered[i] * centered[i]
+	}
+
+	tplStd := math.Sqrt(tplVar)
+	if tplStd < 1.0 {
+		return nil, rw, rh
+	}
+
+	// Σ T'·(I - meanI) = Σ T'·I，因为 Σ T' = 0
+	cross := crossCorrelate(bg.data, bg.w, bg.h, centered, tpl.w, tpl.h)
+	sum, sqSum := integralImages(bg.data, bg.w, bg.h)
+
+	scores := make([]float64, rw*rh)
+	for y := 0; y < rh; y++ {
+		for x := 0; x < rw; x++ {
+			s := rectSum(sum, bg.w+1, x, y, tpl.w, tpl.h)
+			sq := rectSum(sqSum, bg.w+1, x, y, tpl.w, tpl.h)
+			winStd := math.Sqrt(math.Max(sq-s*s/n, 0))
+
+			idx := y*rw + x
+			if winStd < 1.0 {
+				scores[idx] = -2
+				continue
+			}
+			scores[idx] = cross[idx] / (winStd * tplStd)
+		}
+	}
+	return scores, rw, rh
+}
+
+// naiveNCCScoreMap 逐窗口直接求和的 TM_CCOEFF_NORMED，无效位置与返回值约定同 nccScoreMap
+//
+// 复杂度为 O(rw·rh·tw·th)，只作为 FFT 实现的参考，用于一致性测试与基准测试。
+func naiveNCCScoreMap(bg, tpl matchPlane) ([]float64, int, int) {
+	rw, rh := bg.w-tpl.w+1, bg.h-tpl.h+1
+	if rw <= 0 || rh <= 0 {
+		return nil, 0, 0
+	}
+	n := float64(tpl.w * tpl.h)
+
+	var mean, tplVar float64
+	for _, v := range tpl.data {
+		mean += v
+	}
+	mean /= n
+	centered := make([]float64, len(tpl.data))
+	for i, v := range tpl.data {
+		centered[i] = v - mean
+		tplVar += centered[i] * centered[i]
+	}
+
+	tplStd := math.Sqrt(tplVar)
+	if tplStd < 1.0 {
+		return nil, rw, rh
+	}
+
+	scores := make([]float64, rw*rh)
+	for y := 0; y < rh; y++ {
+		for x := 0; x < rw; x++ {
+			var winSum float64
+			for ty := 0; ty < tpl.h; ty++ {
+				row := bg.data[(y+ty)*bg.w+x : (y+ty)*bg.w+x+tpl.w]
+				for _, v := range row {
+					winSum += v
+				}
+			}
+			winMean := winSum / n
+
+			var cross, winVar float64
+			for ty := 0; ty < tpl.h; ty++ {
+				row := bg.data[(y+ty)*bg.w+x : (y+ty)*bg.w+x+tpl.w]
+				for tx, v := range row {
+					d := v - winMean
+					cross += d * centered[ty*tpl.w+tx]
+					winVar += d * d
+				}
+			}
+
+			idx := y*rw + x
+			winStd := math.Sqrt(winVar)
+			if winStd < 1.0 {
+				scores[idx] = -2
+				continue
+			}
+			scores[idx] = cross / (winStd * tplStd)
+		}
+	}
+	return scores, rw, rh
+}
+
+// sqdiffScoreMap 计算 1 - TM_SQDIFF_NORMED 得分图
+//
+// Σ(T-I)² = ΣT² - 2ΣT·I + ΣI²，其中 ΣT·I 用 FFT 计算，ΣI² 用积分图计算。
+// mask 非空时改为带掩码的计算。
+func sqdiffScoreMap(bg, tpl matchPlane, mask []float64) ([]float64, int, int) {
+	rw, rh := bg.w-tpl.w+1, bg.h-tpl.h+1
+	if rw <= 0 || rh <= 0 {
+		return nil, 0, 0
+	}
+	if mask != nil {
+		return maskedSqdiffScoreMap(bg, tpl, mask)
+	}
+
+	var tplSq float64
+	for _, v := range tpl.data {
+		tplSq += v * v
+	}
+	if tplSq < 1.0 {
+		return nil, rw, rh
+	}
+
+	cross := crossCorrelate(bg.data, bg.w, bg.h, tpl.data, tpl.w, tpl.h)
+	_, sqSum := integralImages(bg.data, bg.w, bg.h)
+
+	scores := make([]float64, rw*rh)
+	for y := 0; y < rh; y++ {
+		for x := 0; x < rw; x++ {
+			winSq := rectSum(sqSum, bg.w+1, x, y, tpl.w, tpl.h)
+			idx := y*rw + x
+			denom := math.Sqrt(tplSq * winSq)
+			if denom < 1.0 {
+				scores[idx] = -2
+				continue
+			}
+			diff := math.Max(tplSq-2*cross[idx]+winSq, 0)
+			scores[idx] = 1 - diff/denom
+		}
+	}
+	return scores, rw, rh
+}
+
+// orientationField 计算倍角梯度方向场 (cos 2θ, sin 2θ)
+//
+// 倍角使相反方向的梯度等价，因此对缺口阴影与滑块的明暗极性不敏感；
+// 弱梯度位置为零向量。
+func orientationField(p matchPlane) ([]float64, []float64) {
+	cos2 := make([]float64, len(p.data))
+	sin2 := make([]float64, len(p.data))
+	at := func(x, y int) float64 { return p.data[y*p.w+x] }
+
+	for y := 1; y < p.h-1; y++ {
+		for x := 1; x < p.w-1; x++ {
+			gx := -at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1) +
+				at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1)
+			gy := -at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1) +
+				at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1)
+
+			mag2 := gx*gx + gy*gy
+			if mag2 < gradientMagThreshold*gradientMagThreshold {
+				continue
+			}
+			// cos 2θ = (gx² - gy²)/|g|², sin 2θ = 2·gx·gy/|g|²
+			cos2[y*p.w+x] = (gx*gx - gy*gy) / mag2
+			sin2[y*p.w+x] = 2 * gx * gy / mag2
+		}
+	}
+	return cos2, sin2
+}
+
+// gradientScoreMap 计算梯度方向一致性得分图
+//
+// 得分为模板强梯度像素上 cos(2θT - 2θI) 的平均值，背景弱梯度像素贡献 0。
+// 方向场在整张背景图上计算后再裁剪到 rect，避免区域边界产生伪梯度。
+// mask 非空时只统计掩码内的模板像素。
+func gradientScoreMap(bg, tpl matchPlane, rect image.Rectangle, mask []float64) ([]float64, int, int) {
+	rw, rh := rect.Dx()-tpl.w+1, rect.Dy()-tpl.h+1
+	if rw <= 0 || rh <= 0 {
+		return nil, 0, 0
+	}
+
+	tplCos, tplSin := orientationField(tpl)
+	var strong int
+	for i := range tplCos {
+		if mask != nil && mask[i] == 0 {
+			tplCos[i], tplSin[i] = 0, 0
+			continue
+		}
+		if tplCos[i] != 0 || tplSin[i] != 0 {
+			strong++
+		}
+	}
+	if strong == 0 {
+		return nil, rw, rh
+	}
+
+	bgCos, bgSin := orientationField(bg)
+	cosPlane := matchPlane{data: bgCos, w: bg.w, h: bg.h}.crop(rect)
+	sinPlane := matchPlane{data: bgSin, w: bg.w, h: bg.h}.crop(rect)
+
+	cc := crossCorrelate(cosPlane.data, cosPlane.w, cosPlane.h, tplCos, tpl.w, tpl.h)
+	ss := crossCorrelate(sinPlane.data, sinPlane.w, sinPlane.h, tplSin, tpl.w, tpl.h)
+
+	scores := make([]float64, rw*rh)
+	for i := range scores {
+		scores[i] = (cc[i] + ss[i]) / float64(strong)
+	}
+	return scores, rw, rh
+}
+
+// alphaMaskWeights 由滑块透明通道生成掩码（1 为滑块像素），并向外膨胀 1 像素以包含轮廓边缘
+//
+// 滑块没有透明像素时返回 nil。
+func alphaMaskWeights(img image.Image) []float64 {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	mask := make([]bool, w*h)
+	opaque := 0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			_, _, _, a := img.At(x+bounds.Min.X, y+bounds.Min.Y).RGBA()
+			if a > 0 {
+				mask[y*w+x] = true
+				opaque++
+			}
+		}
+	}
+	if opaque == 0 || opaque == w*h {
+		return nil
+	}
+
+	mask = dilateMask(mask, w, h, 1)
+	weights := make([]float64, w*h)
+	for i, m := range mask {
+		if m {
+			weights[i] = 1
+		}
+	}
+	return weights
+}
+
+// maskedNCCScoreMap 带掩码的 TM_CCOEFF_NORMED
+//
+// 只统计掩码内的 N 个像素：T' = M·(T - mean(T))，
+// 分子 ΣT'·I、窗口的 ΣM·I 与 ΣM·I² 均用 FFT 互相关计算。
+func maskedNCCScoreMap(bg, tpl matchPlane, mask []float64) ([]float64, int, int) {
+	rw, rh := bg.w-tpl.w+1, bg.h-tpl.h+1
+
+	var n, mean float64
+	for i, m := range mask {
+		n += m
+		mean += m * tpl.data[i]
+	}
+	if n == 0 {
+		return nil, rw, rh
+	}
+	mean /= n
+
+	centered := make([]float64, len(tpl.data))
+	var tplVar float64
+	for i, v := range tpl.data {
+		centered[i] = mask[i] * (v - mean)
+		tplVar += centered[i] * centered[i]
+	}
+	tplStd := math.Sqrt(tplVar)
+	if tplStd < 1.0 {
+		return nil, rw, rh
+	}
+
+	bgSq := make([]float64, len(bg.data))
+	for i, v := range bg.data {
+		bgSq[i] = v * v
+	}
+
+	cross := crossCorrelate(bg.data, bg.w, bg.h, centered, tpl.w, tpl.h)
+	sum := crossCorrelate(bg.data, bg.w, bg.h, mask, tpl.w, tpl.h)
+	sqSum := crossCorrelate(bgSq, bg.w, bg.h, mask, tpl.w, tpl.h)
+
+	scores := make([]float64, rw*rh)
+	for i := range scores {
+		winStd := math.Sqrt(math.Max(sqSum[i]-sum[i]*sum[i]/n, 0))
+		if winStd < 1.0 {
+			scores[i] = -2
+			continue
+		}
+		scores[i] = cross[i] / (winStd * tplStd)
+	}
+	return scores, rw, rh
+}
+
+// maskedSqdiffScoreMap 带掩码的 1 - TM_SQDIFF_NORMED
+func maskedSqdiffScoreMap(bg, tpl matchPlane, mask []float64) ([]float64, int, int) {
+	rw, rh := bg.w-tpl.w+1, bg.h-tpl.h+1
+
+	masked := make([]float64, len(tpl.data))
+	var tplSq float64
+	for i, v := range tpl.data {
+		masked[i] = mask[i] * v
+		tplSq += mask[i] * v * v
+	}
+	if tplSq < 1.0 {
+		return nil, rw, rh
+	}
+
+	bgSq := make([]float64, len(bg.data))
+	for i, v := range bg.data {
+		bgSq[i] = v * v
+	}
+
+	cross := crossCorrelate(bg.data, bg.w, bg.h, masked, tpl.w, tpl.h)
+	winSq := crossCorrelate(bgSq, bg.w, bg.h, mask, tpl.w, tpl.h)
+
+	scores := make([]float64, rw*rh)
+	for i := range scores {
+		denom := math.Sqrt(tplSq * math.Max(winSq[i], 0))
+		if denom < 1.0 {
+			scores[i] = -2
+			continue
+		}
+		diff := math.Max(tplSq-2*cross[i]+winSq[i], 0)
+		scores[i] = 1 - diff/denom
+	}
+	return scores, rw, rh
+}
@@ -0,0 +1,184 @@
+package ddddocr
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+	"testing"
+)
+
+// loadSlidePair 读取 testimg 中的滑块图（裁剪到不透明区域）与背景图
+func loadSlidePair(tb testing.TB) (image.Image, image.Image) {
+	tb.Helper()
+	targetBytes, err := os.ReadFile("../testimg/slideImage.png")
+	if err != nil {
+		tb.Skipf("缺少测试图片: %v", err)
+	}
+	bgBytes, err := os.ReadFile("../testimg/bgImage.png")
+	if err != nil {
+		tb.Skipf("缺少测试图片: %v", err)
+	}
+
+	target, _, _, err := getTarget(targetBytes)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	background, _, err := image.Decode(bytes.NewReader(bgBytes))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return target, background
+}
+
+// argmaxPoint 得分图最大值位置，按行优先取第一个
+func argmaxPoint(scores []float64, rw int) image.Point {
+	best := 0
+	for i, v := range scores {
+		if v > scores[best]+1e-9 {
+			best = i
+		}
+	}
+	return image.Point{X: best % rw, Y: best / rw}
+}
+
+func TestNCCScoreMapMatchesNaive(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func(w, h int) matchPlane {
+		p := matchPlane{data: make([]float64, w*h), w: w, h: h}
+		for i := range p.data {
+			p.data[i] = float64(rng.Intn(256))
+		}
+		return p
+	}
+
+	bg, tpl := random(37, 23), random(9, 7)
+	fast, rw, rh := nccScoreMap(bg, tpl, nil)
+	naive, nw, nh := naiveNCCScoreMap(bg, tpl)
+	if rw != nw || rh != nh {
+		t.Fatalf("得分图尺寸 %dx%d, 参考 %dx%d", rw, rh, nw, nh)
+	}
+	for i := range naive {
+		if math.Abs(fast[i]-naive[i]) > 1e-9 {
+			t.Fatalf("位置 %d 得分 %v, 参考 %v", i, fast[i], naive[i])
+		}
+	}
+}
+
+func TestNCCScoreMapArgmaxOnTestImage(t *testing.T) {
+	target, background := loadSlidePair(t)
+
+	planes := map[string][2]matchPlane{
+		"edge": {edgeMatchPlane(background, DefaultCannyOptions()), edgeMatchPlane(target, DefaultCannyOptions())},
+		"gray": {grayMatchPlane(background), grayMatchPlane(target)},
+	}
+	for name, p := range planes {
+		t.Run(name, func(t *testing.T) {
+			fast, rw, _ := nccScoreMap(p[0], p[1], nil)
+			naive, _, _ := naiveNCCScoreMap(p[0], p[1])
+			if fast == nil || naive == nil {
+				t.Fatal("模板无纹理")
+			}
+
+			a, b := argmaxPoint(fast, rw), argmaxPoint(naive, rw)
+			if d := a.Sub(b); d.X < -1 || d.X > 1 || d.Y < -1 || d.Y > 1 {
+				t.Fatalf("FFT 最大值位置 %v, 逐窗口计算 %v", a, b)
+			}
+			i := a.Y*rw + a.X
+			if math.Abs(fast[i]-naive[i]) > 1e-6 {
+				t.Fatalf("最大值得分 %v, 参考 %v", fast[i], naive[i])
+			}
+		})
+	}
+}
+
+// testimg 上的结果应与旧版简化 Canny 的基线 [54 26 97 69] 相差不超过 1 像素
+func TestSlideMatchBaselineOnTestImage(t *testing.T) {
+	targetBytes, err := os.ReadFile("../testimg/slideImage.png")
+	if err != nil {
+		t.Skipf("缺少测试图片: %v", err)
+	}
+	bgBytes, err := os.ReadFile("../testimg/bgImage.png")
+	if err != nil {
+		t.Skipf("缺少测试图片: %v", err)
+	}
+
+	result, err := (&DdddOcr{}).SlideMatch(targetBytes, bgBytes, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseline := []int{54, 26, 97, 69}
+	if len(result.Target) != len(baseline) {
+		t.Fatalf("Target = %v", result.Target)
+	}
+	for i, v := range baseline {
+		if d := result.Target[i] - v; d < -1 || d > 1 {
+			t.Fatalf("Target = %v, 基线 %v", result.Target, baseline)
+		}
+	}
+}
+
+func BenchmarkSlideMatchNaive(b *testing.B) {
+	target, background := loadSlidePair(b)
+	bg, tpl := edgeMatchPlane(background, DefaultCannyOptions()), edgeMatchPlane(target, DefaultCannyOptions())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		naiveNCCScoreMap(bg, tpl)
+	}
+}
+
+func BenchmarkSlideMatchFFT(b *testing.B) {
+	target, background := loadSlidePair(b)
+	bg, tpl := edgeMatchPlane(background, DefaultCannyOptions()), edgeMatchPlane(target, DefaultCannyOptions())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		nccScoreMap(bg, tpl, nil)
+	}
+}
+
+func TestSlideMatchTemplateLargerThanBackground(t *testing.T) {
+	encode := func(w, h int) []byte {
+		img := image.NewGray(image.Rect(0, 0, w, h))
+		for i := range img.Pix {
+			img.Pix[i] = uint8(i * 37)
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	target, background := encode(40, 30), encode(20, 20)
+
+	d := &DdddOcr{}
+	result, err := d.SlideMatch(target, background, true)
+	if err != nil {
+		t.Fatalf("未限制搜索区域时不应报错: %v", err)
+	}
+	if want := []int{0, 0, 40, 30}; !slices.Equal(result.Target, want) {
+		t.Fatalf("Target = %v, want %v", result.Target, want)
+	}
+
+	_, err = d.SlideMatchWithOptions(target, background, SlideMatchOptions{SimpleTarget: true, MinX: 5})
+	if err == nil {
+		t.Fatal("限制搜索区域后区域过小应返回错误")
+	}
+}
+
+func TestAverageScoreMapsSkipsInvalidMethods(t *testing.T) {
+	got := averageScoreMaps([][]float64{
+		{0.8, -2, -2, 0.2},
+		{0.4, 0.6, -2, -0.2},
+	})
+	want := []float64{0.6, 0.6, -2, 0}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-12 {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package ddddocr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// dddd_trainer 项目导入
+// ============================================================================
+
+// NewFromTrainerProject 从 dddd_trainer 项目或导出目录创建识别器
+func NewFromTrainerProject(dir string) (*DdddOcr, error) {
+	return NewFromTrainerProjectWithOptions(dir, DefaultOptions())
+}
+
+// NewFromTrainerProjectWithOptions 带选项从 dddd_trainer 项目创建识别器
+//
+// 依次在 dir、dir/models 中查找导出的 ONNX 模型，字符集优先读取同目录的
+// charsets.json，其次读取项目的 config.yaml（Model 段）。
+// 导入的模型作为 ImportOnnxPath 交给 New，其余选项（Det、ClassifierPath、
+// EmbeddingPath、LazyLoad 等）与 New 的行为一致。
+func NewFromTrainerProjectWithOptions(dir string, opts Options) (*DdddOcr, error) {
+	onnxPath, config, err := findTrainerProject(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.ImportOnnxPath = onnxPath
+	opts.customConfig = &config
+	return New(opts)
+}
+
+// findTrainerProject 查找训练器输出中的模型与字符集配置
+func findTrainerProject(dir string) (string, customModelConfig, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", customModelConfig{}, fmt.Errorf("读取训练项目目录失败: %w", err)
+	}
+	if !info.IsDir() {
+		return "", customModelConfig{}, fmt.Errorf("%s 不是目录", dir)
+	}
+
+	var onnxPath string
+	for _, candidate := range []string{dir, filepath.Join(dir, "models")} {
+		if onnxPath = latestOnnxFile(candidate); onnxPath != "" {
+			break
+		}
+	}
+	if onnxPath == "" {
+		return "", customModelConfig{}, fmt.Errorf(
+			"无法识别的训练项目结构: 在 %s 及其 models 子目录中未找到 .onnx 模型，请先使用 dddd_trainer 导出模型", dir)
+	}
+
+	// 字符集: 模型同目录的 charsets.json -> 项目目录的 charsets.json -> config.yaml
+	onnxDir := filepath.Dir(onnxPath)
+	for _, candidate := range []string{
+		filepath.Join(onnxDir, "charsets.json"),
+		filepath.Join(dir, "charsets.json"),
+	} {
+		data, err := os.ReadFile(candidate)
+		if err != nil {
+			continue
+		}
+		var config customModelConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			return "", customModelConfig{}, fmt.Errorf("解析 %s 失败: %w", candidate, err)
+		}
+		if len(config.Charset) == 0 {
+			return "", customModelConfig{}, fmt.Errorf("%s 中缺少 charset 字段", candidate)
+		}
+		return onnxPath, config, nil
+	}
+
+	for _, candidate := range []string{
+		filepath.Join(dir, "config.yaml"),
+		filepath.Join(filepath.Dir(onnxDir), "config.yaml"),
+	} {
+		data, err := os.ReadFile(candidate)
+		if err != nil {
+			continue
+		}
+		config, err := parseTrainerConfig(data)
+		if err != nil {
+			return "", customModelConfig{}, fmt.Errorf("解析 %s 失败: %w", candidate, err)
+		}
+		return onnxPath, config, nil
+	}
+
+	return "", customModelConfig{}, fmt.Errorf(
+		"无法识别的训练项目结构: 找到模型 %s，但未找到 charsets.json 或 config.yaml", onnxPath)
+}
+
+// latestOnnxFile 返回目录中最近修改的 .onnx 文件（训练器每次导出都会生成新文件）
+func latestOnnxFile(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	var latest string
+	var latestTime int64
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".onnx") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if latest == "" || info.ModTime().UnixNano() > latestTime {
+			latest = filepath.Join(dir, entry.Name())
+			latestTime = info.ModTime().UnixNano()
+		}
+	}
+	return latest
+}
+
+// parseTrainerConfig 解析 dddd_trainer config.yaml 的 Model 段
+//
+// 只支持训练器生成的简单 YAML：标量、流式列表 [a, b] 和块列表 "- a"。
+func parseTrainerConfig(data []byte) (customModelConfig, error) {
+	var config customModelConfig
+	width, height := -1, 64
+	config.Channel = 1
+
+	inModel := false
+	listKey := ""
+	found := false
+
+	for _, rawLine := range strings.Split(string(data), "\n") {
+		line := strings.TrimRight(stripYAMLComment(rawLine), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == 0 && !strings.HasPrefix(trimmed, "- ") {
+			inModel = trimmed == "Model:"
+			found = found || inModel
+			listKey = ""
+			continue
+		}
+		if !inModel {
+			continue
+		}
+
+		// 块列表项
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if listKey == "CharSet" {
+				config.Charset = append(config.Charset, parseYAMLScalar(strings.TrimPrefix(trimmed, "-")))
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		listKey = ""
+
+		switch key {
+		case "CharSet":
+			if value == "" {
+				listKey = key
+			} else {
+				config.Charset = parseYAMLFlowList(value)
+			}
+		case "ImageChannel", "ImageHeight", "ImageWidth":
+			n, err := strconv.Atoi(parseYAMLScalar(value))
+			if err != nil {
+				return config, fmt.Errorf("Model.%s 不是整数: %q", key, value)
+			}
+			switch key {
+			case "ImageChannel":
+				config.Channel = n
+			case "ImageHeight":
+				height = n
+			default:
+				width = n
+			}
+		case "Word":
+			config.Word = value == "true" || value == "True"
+		}
+	}
+
+	if !found {
+		return config, fmt.Errorf("缺少 Model 段")
+	}
+	if len(config.Charset) == 0 {
+		return config, fmt.Errorf("Model.CharSet 为空，请先运行训练器的数据缓存步骤")
+	}
+
+	config.Image = []int{width, height}
+	return config, nil
+}
+
+// parseYAMLFlowList 解析 [a, 'b', "c"] 形式的列表
+func parseYAMLFlowList(value string) []string {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "[")
+	value = strings.TrimSuffix(value, "]")
+
+	var items []string
+	var current strings.Builder
+	var quote byte
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quote != 0:
+			current.WriteByte(c)
+			if c == '\\' && quote == '"' && i+1 < len(value) {
+				i++
+				current.WriteByte(value[i])
+			} else if c == quote {
+				// 单引号内 '' 表示转义的单引号
+				if quote == '\'' && i+1 < len(value) && value[i+1] == '\'' {
+					i++
+					current.WriteByte(value[i])
+				} else {
+					quote = 0
+				}
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			current.WriteByte(c)
+		case c == ',':
+			items = append(items, parseYAMLScalar(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	if strings.TrimSpace(current.String()) != "" || len(items) > 0 {
+		items = append(items, parseYAMLScalar(current.String()))
+	}
+	return items
+}
+
+// stripYAMLComment 去除行尾注释，引号内的 # 以及不在空白之后的 # 不视为注释
+//
+// 只有出现在标量开头的引号才开始引号字符串，don't 之类的撇号不受影响。
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '\'' || c == '"') && (i == 0 || strings.IndexByte(" \t[,:-", line[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseYAMLScalar 去除 YAML 标量的引号
+func parseYAMLScalar(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 {
+		switch {
+		case value[0] == '\'' && value[len(value)-1] == '\'':
+			return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		case value[0] == '"' && value[len(value)-1] == '"':
+			if s, err := strconv.Unquote(value); err == nil {
+				return s
+			}
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
@@ -0,0 +1,148 @@
+package ddddocr
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseYAMLFlowList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{`[a, b, c]`, []string{"a", "b", "c"}},
+		{`['', 'a', "b"]`, []string{"", "a", "b"}},
+		{`[',', "]", '[']`, []string{",", "]", "["}},
+		{`['it''s', "say \"hi\"", "中"]`, []string{"it's", `say "hi"`, "中"}},
+		{`['#', "# x", a#b]`, []string{"#", "# x", "a#b"}},
+		{`[ 1 ,2,  3 ]`, []string{"1", "2", "3"}},
+		{`[]`, nil},
+		{`['']`, []string{""}},
+	}
+
+	for _, tt := range tests {
+		if got := parseYAMLFlowList(tt.value); !slices.Equal(got, tt.want) {
+			t.Fatalf("parseYAMLFlowList(%s) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestStripYAMLComment(t *testing.T) {
+	tests := []struct {
+		line, want string
+	}{
+		{`ImageHeight: 64  # 高度`, `ImageHeight: 64  `},
+		{`# 整行注释`, ``},
+		{`CharSet: ['#', "a # b"] # 注释`, `CharSet: ['#', "a # b"] `},
+		{`Name: a#b`, `Name: a#b`},
+		{`Note: don't # 撇号`, `Note: don't `},
+		{`- "\"#"`, `- "\"#"`},
+	}
+
+	for _, tt := range tests {
+		if got := stripYAMLComment(tt.line); got != tt.want {
+			t.Fatalf("stripYAMLComment(%s) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseTrainerConfig(t *testing.T) {
+	flow := `# dddd_trainer 配置
+Model:
+  CharSet: ['', 'a', "b", '#'] # 由缓存步骤生成
+  ImageChannel: 3
+  ImageHeight: 48   # 高度
+  ImageWidth: -1
+  Word: false
+Train:
+  CharSet: [x]
+  ImageHeight: abc
+`
+	config, err := parseTrainerConfig([]byte(flow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(config.Charset, []string{"", "a", "b", "#"}) {
+		t.Fatalf("字符集 %q", config.Charset)
+	}
+	if config.Channel != 3 || !slices.Equal(config.Image, []int{-1, 48}) || config.Word {
+		t.Fatalf("配置 %+v", config)
+	}
+
+	block := "System:\n  Path: x\nModel:\n  CharSet:\n  - ''\n  - 'it''s'\n  - \"#\"  # 注释\n  - 中\n  Word: True\n"
+	config, err = parseTrainerConfig([]byte(block))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(config.Charset, []string{"", "it's", "#", "中"}) {
+		t.Fatalf("块列表字符集 %q", config.Charset)
+	}
+	if config.Channel != 1 || !slices.Equal(config.Image, []int{-1, 64}) || !config.Word {
+		t.Fatalf("默认配置 %+v", config)
+	}
+}
+
+func TestParseTrainerConfigErrors(t *testing.T) {
+	tests := []struct {
+		name, yaml, want string
+	}{
+		{"缺少 Model 段", "Train:\n  CharSet: [a]\n", "Model"},
+		{"字符集为空", "Model:\n  CharSet: []\n", "CharSet"},
+		{"高度不是整数", "Model:\n  CharSet: [a]\n  ImageHeight: 64px\n", "Model.ImageHeight"},
+		{"宽度不是整数", "Model:\n  CharSet: [a]\n  ImageWidth: auto\n", "Model.ImageWidth"},
+		{"通道不是整数", "Model:\n  CharSet: [a]\n  ImageChannel: 1.5\n", "Model.ImageChannel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTrainerConfig([]byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("错误 %v 应包含 %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindTrainerProject(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "models"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	onnx := writeTestFile(t, filepath.Join(dir, "models"), "ocr_1.onnx", "")
+	writeTestFile(t, dir, "config.yaml", "Model:\n  CharSet: ['', a]\n  ImageHeight: 32\n")
+
+	path, config, err := findTrainerProject(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != onnx || !slices.Equal(config.Charset, []string{"", "a"}) || config.Image[1] != 32 {
+		t.Fatalf("模型 %s 配置 %+v", path, config)
+	}
+
+	// charsets.json 优先于 config.yaml
+	writeTestFile(t, filepath.Join(dir, "models"), "charsets.json", `{"charset": ["", "x", "y"]}`)
+	if _, config, err = findTrainerProject(dir); err != nil || !slices.Equal(config.Charset, []string{"", "x", "y"}) {
+		t.Fatalf("配置 %+v, 错误 %v", config, err)
+	}
+
+	empty := t.TempDir()
+	if _, _, err := findTrainerProject(empty); err == nil || !strings.Contains(err.Error(), ".onnx") {
+		t.Fatalf("缺少模型时错误 %v", err)
+	}
+}
+
+// 训练项目的配置经 Options 传给 New，延迟加载时不再要求 CharsetsPath
+func TestTrainerConfigUsedByLoadOcr(t *testing.T) {
+	config := customModelConfig{Charset: []string{"", "a"}, Image: []int{-1, 64}}
+	d := newLazyTestOcr(Options{
+		ImportOnnxPath: filepath.Join(t.TempDir(), "missing.onnx"),
+		customConfig:   &config,
+	})
+	_, err := d.Classification(testPNG(t))
+	if err == nil || strings.Contains(err.Error(), "字符集") {
+		t.Fatalf("应使用已解析的配置并在加载模型时失败: %v", err)
+	}
+}
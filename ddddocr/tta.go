@@ -0,0 +1,219 @@
+package ddddocr
+
+import (
+	"fmt"
+	"image"
+	"sort"
+
+	"github.com/nfnt/resize"
+)
+
+// ============================================================================
+// 检测测试时增强（TTA）
+// ============================================================================
+
+// TTA 多视图融合方式
+const (
+	TTAFusionWBF = "wbf" // 加权框融合
+	TTAFusionNMS = "nms" // 非极大值抑制
+)
+
+// detectTTA 在多个缩放/翻转视图上检测并融合结果
+func (d *DdddOcr) detectTTA(img image.Image, opts DetectionOptions) ([]Detection, error) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	scales := opts.TTAScales
+	if len(scales) == 0 {
+		scales = []float64{1}
+	}
+	flips := []bool{false}
+	if opts.TTAFlip {
+		flips = append(flips, true)
+	}
+
+	viewOpts := opts
+	viewOpts.MaxDetections = 0
+
+	var views [][]Detection
+	for _, scale := range scales {
+		if scale <= 0 {
+			return nil, fmt.Errorf("无效的 TTA 缩放比例: %v", scale)
+		}
+
+		sw := max(1, int(float64(w)*scale+0.5))
+		sh := max(1, int(float64(h)*scale+0.5))
+		scaled := img
+		if sw != w || sh != h {
+			scaled = resize.Resize(uint(sw), uint(sh), img, resize.Bilinear)
+		}
+		sx := float64(w) / float64(sw)
+		sy := float64(h) / float64(sh)
+
+		for _, flip := range flips {
+			view := scaled
+			if flip {
+				view = flipHorizontal(scaled)
+			}
+
+			candidates, err := d.viewCandidates(view, viewOpts)
+			if err != nil {
+				return nil, err
+			}
+			dets := nmsDetections(candidates, viewOpts)
+
+			// 还原到原图坐标
+			for i := range dets {
+				box := dets[i].Box
+				if flip {
+					box.X1, box.X2 = sw-box.X2, sw-box.X1
+				}
+				dets[i].Box = BBox{
+					X1: max(0, int(float64(box.X1)*sx+0.5)),
+					Y1: max(0, int(float64(box.Y1)*sy+0.5)),
+					X2: min(w, int(float64(box.X2)*sx+0.5)),
+					Y2: min(h, int(float64(box.Y2)*sy+0.5)),
+				}
+			}
+			views = append(views, dets)
+		}
+	}
+
+	var fused []Detection
+	switch opts.TTAFusion {
+	case "", TTAFusionWBF:
+		fused = weightedBoxFusion(views, opts.IoUThreshold, opts.ClassAware)
+	case TTAFusionNMS:
+		fused = fuseViewsNMS(views, opts.IoUThreshold, opts.ClassAware)
+	default:
+		return nil, fmt.Errorf("不支持的融合方式: %s", opts.TTAFusion)
+	}
+
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].Score > fused[j].Score
+	})
+	if opts.MaxDetections > 0 && len(fused) > opts.MaxDetections {
+		fused = fused[:opts.MaxDetections]
+	}
+	return fused, nil
+}
+
+// viewDetection 带视图序号的检测框
+type viewDetection struct {
+	Detection
+	view int
+}
+
+// flattenViews 展开多视图结果并按分数降序排列
+func flattenViews(views [][]Detection) []viewDetection {
+	var all []viewDetection
+	for v, dets := range views {
+		for _, det := range dets {
+			all = append(all, viewDetection{Detection: det, view: v})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Score > all[j].Score
+	})
+	return all
+}
+
+// weightedBoxFusion 加权框融合：按分数加权平均同一目标的各视图框
+func weightedBoxFusion(views [][]Detection, iouThr float32, classAware bool) []Detection {
+	type cluster struct {
+		fused    Detection
+		viewSet  map[int]bool
+		count    int
+		sumScore float64
+		sumBox   [4]float64
+	}
+
+	var clusters []*cluster
+	for _, det := range flattenViews(views) {
+		var best *cluster
+		bestIoU := iouThr
+		for _, c := range clusters {
+			if classAware && c.fused.ClassID != det.ClassID {
+				continue
+			}
+			if iou := computeIoU(c.fused.Box, det.Box); iou > bestIoU {
+				bestIoU = iou
+				best = c
+			}
+		}
+
+		if best == nil {
+			best = &cluster{fused: det.Detection, viewSet: make(map[int]bool)}
+			clusters = append(clusters, best)
+		}
+
+		score := float64(det.Score)
+		best.count++
+		best.viewSet[det.view] = true
+		best.sumScore += score
+		best.sumBox[0] += float64(det.Box.X1) * score
+		best.sumBox[1] += float64(det.Box.Y1) * score
+		best.sumBox[2] += float64(det.Box.X2) * score
+		best.sumBox[3] += float64(det.Box.Y2) * score
+		best.fused.Box = BBox{
+			X1: int(best.sumBox[0]/best.sumScore + 0.5),
+			Y1: int(best.sumBox[1]/best.sumScore + 0.5),
+			X2: int(best.sumBox[2]/best.sumScore + 0.5),
+			Y2: int(best.sumBox[3]/best.sumScore + 0.5),
+		}
+	}
+
+	// 分数 = 平均分 × 检出视图数 / 视图数，只在少数视图中出现的框被降权；
+	// 同一视图内的多个重叠框只计一次，避免抬高分数
+	numViews := float64(len(views))
+	result := make([]Detection, len(clusters))
+	for i, c := range clusters {
+		det := c.fused
+		det.Views = len(c.viewSet)
+		det.Score = float32(c.sumScore / float64(c.count) * float64(det.Views) / numViews)
+		result[i] = det
+	}
+	return result
+}
+
+// fuseViewsNMS 跨视图 NMS，被抑制的框计入保留框的视图数
+func fuseViewsNMS(views [][]Detection, iouThr float32, classAware bool) []Detection {
+	all := flattenViews(views)
+	used := make([]bool, len(all))
+
+	var result []Detection
+	for i := range all {
+		if used[i] {
+			continue
+		}
+		viewSet := map[int]bool{all[i].view: true}
+		for j := i + 1; j < len(all); j++ {
+			if used[j] || (classAware && all[j].ClassID != all[i].ClassID) {
+				continue
+			}
+			if computeIoU(all[i].Box, all[j].Box) > iouThr {
+				used[j] = true
+				viewSet[all[j].view] = true
+			}
+		}
+
+		det := all[i].Detection
+		det.Views = len(viewSet)
+		result = append(result, det)
+	}
+	return result
+}
+
+// flipHorizontal 水平翻转图片
+func flipHorizontal(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	result := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			result.Set(w-1-x, y, img.At(x+bounds.Min.X, y+bounds.Min.Y))
+		}
+	}
+	return result
+}
@@ -29,6 +29,12 @@ func main() {
 	//
 	//// 测试 4: 滑块识别并发
 	//testSlideMatchConcurrent()
+	//
+	//// 测试 5: 滑块识别单次耗时
+	//testSlideMatchLatency()
+	//
+	//// 测试 6: 滑块匹配方法对比
+	//testSlideMethods()
 }
 
 // ============================================================================
@@ -326,3 +332,115 @@ func testSlideMatchConcurrent() {
 	fmt.Printf("  成功: %d, 失败: %d\n", successCount, errorCount)
 	fmt.Printf("  耗时: %v, QPS: %.2f\n\n", elapsed, qps)
 }
+
+// ============================================================================
+// 测试 5: 滑块识别单次耗时
+// ============================================================================
+
+func testSlideMatchLatency() {
+	fmt.Println("=== 测试 5: 滑块识别单次耗时 ===")
+
+	targetBytes, err := os.ReadFile("./testimg/slideImage.png")
+	if err != nil {
+		fmt.Printf("读取滑块图失败: %v\n", err)
+		return
+	}
+
+	bgBytes, err := os.ReadFile("./testimg/bgImage.png")
+	if err != nil {
+		fmt.Printf("读取背景图失败: %v\n", err)
+		return
+	}
+
+	opts := ddddocr.DefaultOptions()
+	opts.Ocr = false
+	opts.Det = false
+	opts.ModelDir = "./models/"
+	slide, err := ddddocr.New(opts)
+	if err != nil {
+		fmt.Printf("创建滑块识别器失败: %v\n", err)
+		return
+	}
+	defer slide.Close()
+
+	iterations := 50
+	for _, simpleTarget := range []bool{false, true} {
+		var result *ddddocr.SlideMatchResult
+		start := time.Now()
+		for i := 0; i < iterations; i++ {
+			result, err = slide.SlideMatch(targetBytes, bgBytes, simpleTarget)
+			if err != nil {
+				fmt.Printf("  滑块识别失败: %v\n", err)
+				return
+			}
+		}
+		elapsed := time.Since(start)
+
+		fmt.Printf("  simpleTarget=%v, 结果: %v\n", simpleTarget, result.Target)
+		fmt.Printf("  迭代: %d, 平均耗时: %v\n", iterations, elapsed/time.Duration(iterations))
+	}
+	fmt.Println()
+}
+
+// ============================================================================
+// 测试 6: 滑块匹配方法对比
+// ============================================================================
+
+func testSlideMethods() {
+	fmt.Println("=== 测试 6: 滑块匹配方法对比 ===")
+
+	targetBytes, err := os.ReadFile("./testimg/slideImage.png")
+	if err != nil {
+		fmt.Printf("读取滑块图失败: %v\n", err)
+		return
+	}
+
+	bgBytes, err := os.ReadFile("./testimg/bgImage.png")
+	if err != nil {
+		fmt.Printf("读取背景图失败: %v\n", err)
+		return
+	}
+
+	opts := ddddocr.DefaultOptions()
+	opts.Ocr = false
+	opts.Det = false
+	opts.ModelDir = "./models/"
+	slide, err := ddddocr.New(opts)
+	if err != nil {
+		fmt.Printf("创建滑块识别器失败: %v\n", err)
+		return
+	}
+	defer slide.Close()
+
+	methods := []string{
+		ddddocr.SlideMethodEdge,
+		ddddocr.SlideMethodGray,
+		ddddocr.SlideMethodSqDiff,
+		ddddocr.SlideMethodGradient,
+		ddddocr.SlideMethodVote,
+	}
+
+	iterations := 20
+	fmt.Printf("  %-10s %-8s %-22s %-8s %-8s %s\n", "方法", "simple", "位置", "得分", "领先", "平均耗时")
+	for _, simpleTarget := range []bool{false, true} {
+		for _, method := range methods {
+			var result *ddddocr.SlideMatchResult
+			start := time.Now()
+			for i := 0; i < iterations; i++ {
+				result, err = slide.SlideMatchWithOptions(targetBytes, bgBytes, ddddocr.SlideMatchOptions{
+					SimpleTarget: simpleTarget,
+					Method:       method,
+				})
+				if err != nil {
+					fmt.Printf("  %s 匹配失败: %v\n", method, err)
+					return
+				}
+			}
+			elapsed := time.Since(start) / time.Duration(iterations)
+
+			fmt.Printf("  %-10s %-8v %-22v %-8.3f %-8.3f %v\n",
+				method, simpleTarget, result.Target, result.Score, result.Margin, elapsed)
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,45 @@
+#!/usr/bin/env python3
+# 生成 Canny 参考边缘图，供 ddddocr/canny_test.go 比较
+# 使用方法: pip install opencv-python && python3 scripts/canny_golden.py
+#
+# 输入图先由 OpenCV 解码再无损保存为 PNG，Go 测试读取同一份像素，
+# 避免 JPEG 解码差异影响比较。用例需与 canny_test.go 中的 cannyGoldenCases 保持一致。
+
+import os
+
+import cv2
+
+ROOT = os.path.dirname(os.path.dirname(os.path.abspath(__file__)))
+OUT_DIR = os.path.join(ROOT, "ddddocr", "testdata", "canny")
+
+INPUTS = ["bgImage"]
+
+
+def cases(bgr):
+    gray = cv2.cvtColor(bgr, cv2.COLOR_BGR2GRAY)
+    return {
+        "gray": cv2.Canny(gray, 100, 200),
+        "color": cv2.Canny(bgr, 100, 200),
+        "blur5": cv2.Canny(cv2.GaussianBlur(gray, (5, 5), 0), 100, 200),
+        "blur9": cv2.Canny(cv2.GaussianBlur(gray, (9, 9), 0), 50, 150),
+        "l2_ap5": cv2.Canny(gray, 400, 1000, apertureSize=5, L2gradient=True),
+    }
+
+
+def main():
+    os.makedirs(OUT_DIR, exist_ok=True)
+    for name in INPUTS:
+        src = os.path.join(ROOT, "testimg", name + ".png")
+        bgr = cv2.imread(src, cv2.IMREAD_COLOR)
+        if bgr is None:
+            raise SystemExit("无法读取 " + src)
+
+        cv2.imwrite(os.path.join(OUT_DIR, name + "_input.png"), bgr)
+        for case, edges in cases(bgr).items():
+            cv2.imwrite(os.path.join(OUT_DIR, "%s_%s.png" % (name, case)), edges)
+        print("✅ " + name)
+    print("OpenCV " + cv2.__version__ + "，输出目录: " + OUT_DIR)
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,103 @@
+//go:build ignore
+
+// 检测模型评估工具
+//
+//	go run scripts/eval_detection.go -images data/images -yolo data/labels
+//	go run scripts/eval_detection.go -coco data/annotations.json -model my_det.onnx -config my_det.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yangbin1322/go-ddddocr/ddddocr"
+)
+
+func main() {
+	imagesDir := flag.String("images", "", "图片目录（YOLO 必填；COCO 可选，用于覆盖图片所在目录）")
+	yoloDir := flag.String("yolo", "", "YOLO 标注目录")
+	cocoPath := flag.String("coco", "", "COCO 标注文件")
+	modelDir := flag.String("models", ddddocr.DefaultModelDir, "模型目录")
+	modelPath := flag.String("model", "", "自定义检测模型路径，留空使用 common_det.onnx")
+	configPath := flag.String("config", "", "自定义检测模型配置路径")
+	iou := flag.Float64("iou", 0.5, "Precision/Recall 的 IoU 阈值")
+	score := flag.Float64("score", 0, "Precision/Recall 的最低分数")
+	agnostic := flag.Bool("agnostic", false, "忽略类别（默认按类别匹配，无类别名的预测按标注中的类别序号映射）")
+	worst := flag.Int("worst", 10, "列出的最差图片数量")
+	jsonOut := flag.String("json", "", "将完整报告写入 JSON 文件")
+	flag.Parse()
+
+	var groundTruth []ddddocr.AnnotatedImage
+	var err error
+	switch {
+	case *cocoPath != "":
+		groundTruth, _, err = ddddocr.ImportCOCO(*cocoPath)
+		if err == nil && *imagesDir != "" {
+			for i := range groundTruth {
+				groundTruth[i].Path = filepath.Join(*imagesDir, filepath.Base(groundTruth[i].Path))
+			}
+		}
+	case *yoloDir != "" && *imagesDir != "":
+		groundTruth, _, err = ddddocr.ImportYOLO(*imagesDir, *yoloDir)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Printf("❌ 读取标注失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := ddddocr.DefaultOptions()
+	opts.Ocr = false
+	opts.Det = true
+	opts.ModelDir = *modelDir
+	opts.DetModelPath = *modelPath
+	opts.DetConfigPath = *configPath
+	det, err := ddddocr.New(opts)
+	if err != nil {
+		fmt.Printf("❌ 创建检测器失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer det.Close()
+
+	report, err := det.EvaluateDetection(groundTruth, ddddocr.DetectionOptions{}, ddddocr.EvalOptions{
+		IoUThreshold:   *iou,
+		ScoreThreshold: float32(*score),
+		ClassAgnostic:  *agnostic,
+		WorstN:         *worst,
+	})
+	if err != nil {
+		fmt.Printf("❌ 评估失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("==========================================")
+	fmt.Printf("图片数: %d\n", len(groundTruth))
+	fmt.Printf("IoU=%.2f  TP=%d  FP=%d  FN=%d\n", report.IoUThreshold, report.TP, report.FP, report.FN)
+	fmt.Printf("Precision=%.4f  Recall=%.4f  F1=%.4f\n", report.Precision, report.Recall, report.F1)
+	fmt.Printf("mAP@0.5=%.4f  mAP@0.5:0.95=%.4f\n", report.MAP50, report.MAP5095)
+	fmt.Println("------------------------------------------")
+	for _, c := range report.PerClass {
+		fmt.Printf("%-16s GT=%-5d AP50=%.4f  AP50:95=%.4f\n", c.Class, c.NumGT, c.AP50, c.AP5095)
+	}
+	if len(report.WorstImages) > 0 {
+		fmt.Println("------------------------------------------")
+		fmt.Println("最差图片:")
+		for _, img := range report.WorstImages {
+			fmt.Printf("  %s  TP=%d FP=%d FN=%d F1=%.3f\n", img.Path, img.TP, img.FP, img.FN, img.F1)
+		}
+	}
+	fmt.Println("==========================================")
+
+	if *jsonOut != "" {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		if err := os.WriteFile(*jsonOut, data, 0644); err != nil {
+			fmt.Printf("❌ 写入报告失败: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}